@@ -15,8 +15,13 @@
 package pyth
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
@@ -61,6 +66,38 @@ func PeekAccount(data []byte) uint32 {
 	return header.AccountType
 }
 
+// ParseAccount decodes any Pyth account given the account's data bytes.
+//
+// Dispatches on the account type in the header and returns either
+// a *PriceAccount, a *ProductAccount, or a *MappingAccount.
+// Useful to decode results of getProgramAccounts.
+func ParseAccount(data []byte) (interface{}, error) {
+	switch accountType := PeekAccount(data); accountType {
+	case AccountTypeMapping:
+		acc := new(MappingAccount)
+		if err := acc.UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+		return acc, nil
+	case AccountTypeProduct:
+		acc := new(ProductAccount)
+		if err := acc.UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+		return acc, nil
+	case AccountTypePrice:
+		acc := new(PriceAccount)
+		if err := acc.UnmarshalBinary(data); err != nil {
+			return nil, err
+		}
+		return acc, nil
+	case AccountTypeUnknown:
+		return nil, errors.New("invalid account")
+	default:
+		return nil, fmt.Errorf("unsupported account type (%d)", accountType)
+	}
+}
+
 type ProductAccountHeader struct {
 	AccountHeader `json:"-"`
 	FirstPrice    solana.PublicKey `json:"first_price"` // first price account in list
@@ -76,9 +113,14 @@ type ProductAccount struct {
 	Attrs AttrsMap `json:"attrs"` // key-value string pairs of additional data
 }
 
+// ProductAccountAttrsLen is the maximum binary length of the attributes of a product account.
+//
+// Product accounts are not chained, so all attributes of a product must fit into a single account.
+const ProductAccountAttrsLen = 464
+
 type RawProductAccount struct {
 	ProductAccountHeader
-	AttrsData [464]byte
+	AttrsData [ProductAccountAttrsLen]byte
 }
 
 // UnmarshalJSON decodes the product account contents from JSON.
@@ -142,9 +184,9 @@ type Ema struct {
 //
 // This struct can represent either a publisher's contribution or the outcome of price aggregation.
 type PriceInfo struct {
-	Price   int64  // current price
-	Conf    uint64 // confidence interval around the price
-	Status  uint32 // status of price
+	Price   int64       // current price
+	Conf    uint64      // confidence interval around the price
+	Status  PriceStatus // status of price
 	CorpAct uint32
 	PubSlot uint64 // valid publishing slot
 }
@@ -155,10 +197,11 @@ func (p *PriceInfo) IsZero() bool {
 
 // Value returns the parsed price and conf values.
 //
+// Values are exact and do not overflow, regardless of the exponent.
 // If ok is false, the value is invalid.
 func (p *PriceInfo) Value(exponent int32) (price decimal.Decimal, conf decimal.Decimal, ok bool) {
 	price = decimal.New(p.Price, exponent)
-	conf = decimal.New(int64(p.Conf), exponent)
+	conf = decimal.NewFromBigInt(new(big.Int).SetUint64(p.Conf), exponent)
 	ok = p.Status == PriceStatusTrading
 	return
 }
@@ -168,14 +211,89 @@ func (p *PriceInfo) HasChanged(other *PriceInfo) bool {
 	return (p == nil) != (other == nil) || p.Status != other.Status || p.PubSlot != other.PubSlot
 }
 
+// PriceType describes the type of price or calculation of a price account.
+//
+// Stored as the PriceType field of price accounts and add_price/init_price payloads.
+type PriceType uint32
+
+// Price type.
+const (
+	PriceTypeUnknown = PriceType(iota)
+	PriceTypePrice
+)
+
+// String returns a human-readable name of the price type.
+func (t PriceType) String() string {
+	switch t {
+	case PriceTypeUnknown:
+		return "unknown"
+	case PriceTypePrice:
+		return "price"
+	default:
+		return fmt.Sprintf("unsupported (%d)", uint32(t))
+	}
+}
+
+// PriceTypeValue returns the on-chain numeric value of a price type.
+func PriceTypeValue(t PriceType) uint32 {
+	return uint32(t)
+}
+
+// ParsePriceType returns the price type with the given on-chain numeric value.
+func ParsePriceType(v uint32) (PriceType, error) {
+	if v > uint32(PriceTypePrice) {
+		return PriceTypeUnknown, fmt.Errorf("unsupported price type (%d)", v)
+	}
+	return PriceType(v), nil
+}
+
+// PriceStatus describes the trading status of a price.
+type PriceStatus uint32
+
 // Price status.
 const (
-	PriceStatusUnknown = uint32(iota)
+	PriceStatusUnknown = PriceStatus(iota)
 	PriceStatusTrading
 	PriceStatusHalted
 	PriceStatusAuction
 )
 
+// String returns a human-readable name of the price status.
+func (s PriceStatus) String() string {
+	switch s {
+	case PriceStatusUnknown:
+		return "unknown"
+	case PriceStatusTrading:
+		return "trading"
+	case PriceStatusHalted:
+		return "halted"
+	case PriceStatusAuction:
+		return "auction"
+	default:
+		return fmt.Sprintf("unsupported (%d)", uint32(s))
+	}
+}
+
+// PriceStatusValue returns the on-chain numeric value of a price status.
+func PriceStatusValue(s PriceStatus) uint32 {
+	return uint32(s)
+}
+
+// ParsePriceStatus returns the price status with the given on-chain numeric value.
+func ParsePriceStatus(v uint32) (PriceStatus, error) {
+	if v > uint32(PriceStatusAuction) {
+		return PriceStatusUnknown, fmt.Errorf("unsupported price status (%d)", v)
+	}
+	return PriceStatus(v), nil
+}
+
+// IsUsable returns whether a price with this status may be consumed.
+//
+// Trading prices are always usable. Auction prices are only usable if allowAuction is set.
+func (s PriceStatus) IsUsable(allowAuction bool) bool {
+	return s == PriceStatusTrading || (allowAuction && s == PriceStatusAuction)
+}
+
 // PriceComp contains the price and confidence contributed by a specific publisher.
 type PriceComp struct {
 	Publisher solana.PublicKey // key of contributing publisher
@@ -187,7 +305,7 @@ type PriceComp struct {
 type PriceAccount struct {
 	AccountHeader
 	PriceType  uint32           // price or calculation type
-	Exponent   int32            // price exponent
+	Expo       int32            `json:"Exponent"` // price exponent, use Exponent()
 	Num        uint32           // number of component prices
 	NumQt      uint32           // number of quoters that make up aggregate
 	LastSlot   uint64           // slot of last valid (not unknown) aggregate price
@@ -203,8 +321,23 @@ type PriceAccount struct {
 	Drv3       int64            // reserved for future use
 	Agg        PriceInfo        // aggregate price info
 	Components [32]PriceComp    // price components for each quoter
+
+	Cumulative *PriceCumulative `bin:"-"` // running sums for TWAP, nil if not present
+}
+
+// PriceAccountLen is the binary size of a PriceAccount, excluding trailing cumulative fields.
+const PriceAccountLen = 3312
+
+// PriceCumulative contains running sums of the aggregate price, stored past the end of newer price accounts.
+type PriceCumulative struct {
+	Price   *big.Int // sum of aggregate price times slot gap
+	Conf    *big.Int // sum of aggregate confidence times slot gap
+	NumDown uint64   // number of slots without a valid aggregate price
 }
 
+// priceCumulativeLen is the binary size of PriceCumulative, including padding.
+const priceCumulativeLen = 48
+
 // UnmarshalBinary decodes the price account from the on-chain format.
 func (p *PriceAccount) UnmarshalBinary(buf []byte) error {
 	decoder := bin.NewBinDecoder(buf)
@@ -217,9 +350,47 @@ func (p *PriceAccount) UnmarshalBinary(buf []byte) error {
 	if p.AccountType != AccountTypePrice {
 		return errors.New("not a price account")
 	}
+	p.Cumulative = nil
+	if len(buf) >= PriceAccountLen+priceCumulativeLen {
+		p.Cumulative = decodePriceCumulative(buf[PriceAccountLen : PriceAccountLen+priceCumulativeLen])
+	}
 	return nil
 }
 
+// decodePriceCumulative decodes the trailing cumulative fields of a price account.
+func decodePriceCumulative(buf []byte) *PriceCumulative {
+	return &PriceCumulative{
+		Price:   decodeInt128(buf[0:16], true),
+		Conf:    decodeInt128(buf[16:32], false),
+		NumDown: binary.LittleEndian.Uint64(buf[32:40]),
+	}
+}
+
+// decodeInt128 decodes a little-endian 128-bit integer.
+func decodeInt128(buf []byte, signed bool) *big.Int {
+	be := make([]byte, len(buf))
+	for i, b := range buf {
+		be[len(buf)-1-i] = b
+	}
+	v := new(big.Int).SetBytes(be)
+	if signed && be[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return v
+}
+
+// Exponent returns the exponent to apply to all prices and confidence intervals of the price account.
+//
+// A price value is its integer price multiplied by 10^Exponent.
+func (p *PriceAccount) Exponent() int32 {
+	return p.Expo
+}
+
+// ProductKey returns the key of the product account this price account belongs to.
+func (p *PriceAccount) ProductKey() solana.PublicKey {
+	return p.Product
+}
+
 // GetComponent returns the first price component with the given publisher key. Might return nil.
 func (p *PriceAccount) GetComponent(publisher *solana.PublicKey) *PriceComp {
 	for i := range p.Components {
@@ -230,6 +401,337 @@ func (p *PriceAccount) GetComponent(publisher *solana.PublicKey) *PriceComp {
 	return nil
 }
 
+// CanPublish returns whether the publisher has a component slot in the price account.
+//
+// Price updates of other publishers are rejected, or silently ignored by Instruction_UpdPriceNoFailOnError.
+func (p *PriceAccount) CanPublish(publisher solana.PublicKey) bool {
+	for _, comp := range p.ValidComponents() {
+		if comp.Publisher == publisher {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxComponents returns the number of publisher slots of the price account.
+//
+// Returns zero if the account version is not supported.
+func (p *PriceAccount) MaxComponents() int {
+	if p.Version != V2 {
+		return 0
+	}
+	return len(p.Components)
+}
+
+// MinPublishers returns the minimum number of publishers required to produce an aggregate price.
+//
+// Set via Instruction_SetMinPub and stored in the lowest byte of the Drv2 field.
+func (p *PriceAccount) MinPublishers() uint8 {
+	return uint8(p.Drv2)
+}
+
+// Derivations returns the reserved derivation fields drv1 to drv5 of the on-chain price account, in layout order.
+//
+// Drv2 packs the on-chain fields min_pub (u8), drv2 (i8), drv3 (i16) and drv4 (i32).
+// These are unpacked, except for min_pub, see MinPublishers.
+// Drv3 holds the on-chain field drv5.
+// The returned slice has a length of 5 and is owned by the caller.
+func (p *PriceAccount) Derivations() []int64 {
+	return []int64{
+		p.Drv1,
+		int64(int8(p.Drv2 >> 8)),
+		int64(int16(p.Drv2 >> 16)),
+		int64(int32(p.Drv2 >> 32)),
+		p.Drv3,
+	}
+}
+
+// IsInitialized returns whether the price account received any price update.
+//
+// Freshly created price accounts have a zero aggregate price and publish slot.
+// Their prices must not be interpreted as a real zero price.
+func (p *PriceAccount) IsInitialized() bool {
+	if p.Agg.PubSlot != 0 {
+		return true
+	}
+	for _, comp := range p.ValidComponents() {
+		if comp.Latest.PubSlot != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultSlotDuration is the approximate target duration of a Solana slot.
+const DefaultSlotDuration = 400 * time.Millisecond
+
+// AgeSeconds returns the wall-clock time elapsed since the aggregate price was published.
+//
+// The slot gap is converted using slotDuration, or DefaultSlotDuration if zero.
+// Returns zero if currentSlot is not after the publish slot.
+func (p *PriceAccount) AgeSeconds(currentSlot uint64, slotDuration time.Duration) time.Duration {
+	if slotDuration == 0 {
+		slotDuration = DefaultSlotDuration
+	}
+	if currentSlot <= p.Agg.PubSlot {
+		return 0
+	}
+	return time.Duration(currentSlot-p.Agg.PubSlot) * slotDuration
+}
+
+// ValidComponents returns the slice of price components in use, excluding empty slots.
+func (p *PriceAccount) ValidComponents() []PriceComp {
+	if p.Num > uint32(p.MaxComponents()) {
+		return nil
+	}
+	return p.Components[:p.Num]
+}
+
+// PublisherSlots returns the publish slot of the latest price of each publisher with a component slot.
+func (p *PriceAccount) PublisherSlots() map[solana.PublicKey]uint64 {
+	comps := p.ValidComponents()
+	slots := make(map[solana.PublicKey]uint64, len(comps))
+	for _, comp := range comps {
+		slots[comp.Publisher] = comp.Latest.PubSlot
+	}
+	return slots
+}
+
+// SelfCheck verifies the internal consistency of the price account and returns the first inconsistency found.
+//
+// Use this check to reject corrupted or crafted account data before trusting its prices.
+func (p *PriceAccount) SelfCheck() error {
+	if p.Num > uint32(p.MaxComponents()) {
+		return fmt.Errorf("number of components %d exceeds maximum of %d", p.Num, p.MaxComponents())
+	}
+	if p.NumQt > p.Num {
+		return fmt.Errorf("number of quoters %d exceeds number of components %d", p.NumQt, p.Num)
+	}
+	if err := validateExponent(p.Expo); err != nil {
+		return err
+	}
+	for i := p.Num; i < uint32(len(p.Components)); i++ {
+		if p.Components[i] != (PriceComp{}) {
+			return fmt.Errorf("component #%d past number of components %d is not empty", i, p.Num)
+		}
+	}
+	return nil
+}
+
+// ConfidenceBps returns the aggregate confidence interval in basis points of the aggregate price.
+//
+// If ok is false, the aggregate price is zero or not trading.
+func (p *PriceAccount) ConfidenceBps() (bps float64, ok bool) {
+	if p.Agg.Price == 0 || p.Agg.Status != PriceStatusTrading {
+		return 0, false
+	}
+	return float64(p.Agg.Conf) / math.Abs(float64(p.Agg.Price)) * 10000, true
+}
+
+// IsConfidenceAcceptable returns whether the aggregate confidence interval is at most maxBps basis points of the price.
+//
+// Returns false if the aggregate price is zero or not trading, see ConfidenceBps.
+func (p *PriceAccount) IsConfidenceAcceptable(maxBps float64) bool {
+	bps, ok := p.ConfidenceBps()
+	return ok && bps <= maxBps
+}
+
+// IsLargeMove returns whether the aggregate price moved by more than thresholdBps basis points
+// since the previous aggregate price, along with the size of the move in basis points.
+//
+// Both prices share the exponent of the price account.
+// Returns false and zero if the aggregate price is not trading or the previous price is zero.
+func (p *PriceAccount) IsLargeMove(thresholdBps float64) (bool, float64) {
+	if p.PrevPrice == 0 || p.Agg.Status != PriceStatusTrading {
+		return false, 0
+	}
+	delta := float64(p.Agg.Price) - float64(p.PrevPrice)
+	bps := math.Abs(delta) / math.Abs(float64(p.PrevPrice)) * 10000
+	return bps > thresholdBps, bps
+}
+
+// PriceRat returns the exact aggregate price as a rational number, after applying the exponent.
+//
+// The aggregate price status is not checked.
+func (p *PriceAccount) PriceRat() *big.Rat {
+	price := new(big.Rat).SetInt64(p.Agg.Price)
+	return price.Mul(price, pow10Rat(p.Exponent()))
+}
+
+// PriceRounded returns the aggregate price after applying the exponent,
+// rounded to the given number of decimal places using banker's rounding.
+//
+// If ok is false, the aggregate price is not trading.
+func (p *PriceAccount) PriceRounded(places int) (price float64, ok bool) {
+	value, _, ok := p.Agg.Value(p.Exponent())
+	price, _ = value.RoundBank(int32(places)).Float64()
+	return price, ok
+}
+
+// TWAP returns the time-weighted average aggregate price between a previous snapshot and this one.
+//
+// Both snapshots must contain cumulative fields.
+// The average is taken over the slots between the publish slots of both aggregate prices.
+func (p *PriceAccount) TWAP(prev *PriceAccount) (float64, error) {
+	if p.Cumulative == nil || prev.Cumulative == nil {
+		return 0, errors.New("price account has no cumulative fields")
+	}
+	if p.Agg.PubSlot <= prev.Agg.PubSlot {
+		return 0, fmt.Errorf("snapshot at slot %d is not after previous at slot %d",
+			p.Agg.PubSlot, prev.Agg.PubSlot)
+	}
+	slots := new(big.Int).SetUint64(p.Agg.PubSlot - prev.Agg.PubSlot)
+	sum := new(big.Int).Sub(p.Cumulative.Price, prev.Cumulative.Price)
+	twap := new(big.Rat).SetFrac(sum, slots)
+	f, _ := twap.Mul(twap, pow10Rat(p.Exponent())).Float64()
+	return f, nil
+}
+
+// PriceJSON is the lossless JSON representation of a price and confidence interval.
+//
+// Price and conf are scaled by the exponent and encoded as decimal strings to avoid float precision loss.
+type PriceJSON struct {
+	Price  decimal.Decimal `json:"price"`
+	Conf   decimal.Decimal `json:"conf"`
+	Expo   int32           `json:"expo"`
+	Status string          `json:"status"`
+}
+
+// NewPriceJSON returns the JSON representation of a price info with the given exponent.
+func NewPriceJSON(info *PriceInfo, exponent int32) PriceJSON {
+	price, conf, _ := info.Value(exponent)
+	return PriceJSON{
+		Price:  price,
+		Conf:   conf,
+		Expo:   exponent,
+		Status: info.Status.String(),
+	}
+}
+
+// PriceAccountJSON is a JSON representation of the prices of a PriceAccount, see PriceAccount.PriceJSON.
+type PriceAccountJSON struct {
+	Product    solana.PublicKey `json:"product"`
+	Next       solana.PublicKey `json:"next"`
+	PubSlot    uint64           `json:"pub_slot"`
+	Agg        PriceJSON        `json:"agg"`
+	Components []PriceCompJSON  `json:"components"`
+}
+
+// PriceCompJSON is a JSON representation of the latest price of a PriceComp.
+type PriceCompJSON struct {
+	Publisher solana.PublicKey `json:"publisher"`
+	PubSlot   uint64           `json:"pub_slot"`
+	Latest    PriceJSON        `json:"latest"`
+}
+
+// PriceJSON returns the aggregate and latest component prices of the price account as lossless decimal strings.
+//
+// Unlike the full JSON encoding of PriceAccount, this only contains prices and their links,
+// omitting other fields such as MinPublishers, NumQt, Twap, Twac, ValidSlot and previous prices.
+// See PriceJSON.
+func (p *PriceAccount) PriceJSON() PriceAccountJSON {
+	comps := p.ValidComponents()
+	out := PriceAccountJSON{
+		Product:    p.Product,
+		Next:       p.Next,
+		PubSlot:    p.Agg.PubSlot,
+		Agg:        NewPriceJSON(&p.Agg, p.Exponent()),
+		Components: make([]PriceCompJSON, len(comps)),
+	}
+	for i := range comps {
+		out.Components[i] = PriceCompJSON{
+			Publisher: comps[i].Publisher,
+			PubSlot:   comps[i].Latest.PubSlot,
+			Latest:    NewPriceJSON(&comps[i].Latest, p.Exponent()),
+		}
+	}
+	return out
+}
+
+// priceAccountFields has the fields of PriceAccount, but none of its methods.
+//
+// Used to encode all fields of a PriceAccount to JSON with the default encoding.
+type priceAccountFields PriceAccount
+
+// MarshalJSON encodes all fields of the price account to JSON.
+//
+// Alongside the default encoding of each field, the "Prices" key holds the lossless decimal prices
+// as returned by PriceJSON, which is ignored when decoding.
+func (p *PriceAccount) MarshalJSON() ([]byte, error) {
+	fields, prices := priceAccountJSON(p)
+	return json.Marshal(struct {
+		*priceAccountFields
+		Prices *PriceAccountJSON `json:",omitempty"`
+	}{fields, prices})
+}
+
+// priceAccountJSON returns the parts of the JSON encoding of a price account, or nil if the account is nil.
+//
+// Types embedding *PriceAccount use this in their MarshalJSON,
+// since the promoted PriceAccount.MarshalJSON would drop their own fields.
+func priceAccountJSON(p *PriceAccount) (*priceAccountFields, *PriceAccountJSON) {
+	if p == nil {
+		return nil, nil
+	}
+	prices := p.PriceJSON()
+	return (*priceAccountFields)(p), &prices
+}
+
+// pow10Rat returns 10^exp as an exact rational number.
+func pow10Rat(exp int32) *big.Rat {
+	abs := int64(exp)
+	if abs < 0 {
+		abs = -abs
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs), nil)
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), scale)
+	}
+	return new(big.Rat).SetInt(scale)
+}
+
+// ConfTracker tracks the confidence interval of a price feed across observations.
+//
+// The zero value is ready to use. It is not safe for concurrent use.
+type ConfTracker struct {
+	lastBps float64
+	hasLast bool
+}
+
+// Observe records the confidence interval of the price account, as returned by PriceAccount.ConfidenceBps.
+//
+// Returns the change in basis points since the last observation.
+// Returns zero on the first observation, and if the price account is not trading.
+// Observations of accounts that are not trading are otherwise ignored.
+func (t *ConfTracker) Observe(acc *PriceAccount) (deltaBps float64) {
+	bps, ok := acc.ConfidenceBps()
+	if !ok {
+		return 0
+	}
+	if t.hasLast {
+		deltaBps = bps - t.lastBps
+	}
+	t.lastBps = bps
+	t.hasLast = true
+	return deltaBps
+}
+
+// ComparePrices compares the aggregate prices of two price accounts after applying their exponents.
+//
+// Returns -1 if a is less than b, 0 if both are equal, and 1 if a is greater than b.
+// Returns an error if the aggregate price of either account is not trading.
+func ComparePrices(a *PriceAccount, b *PriceAccount) (int, error) {
+	aPrice, _, ok := a.Agg.Value(a.Exponent())
+	if !ok {
+		return 0, errors.New("first price is not trading")
+	}
+	bPrice, _, ok := b.Agg.Value(b.Exponent())
+	if !ok {
+		return 0, errors.New("second price is not trading")
+	}
+	return aPrice.Cmp(bPrice), nil
+}
+
 // MappingAccount is a piece of a singly linked-list of all products on Pyth.
 type MappingAccount struct {
 	AccountHeader
@@ -254,6 +756,11 @@ func (m *MappingAccount) UnmarshalBinary(buf []byte) error {
 	return nil
 }
 
+// IsFull returns whether the mapping account has no space left for more product keys.
+func (m *MappingAccount) IsFull() bool {
+	return m.Num >= uint32(len(m.Products))
+}
+
 // ProductKeys returns the slice of product keys referenced by this mapping, excluding empty entries.
 func (m *MappingAccount) ProductKeys() []solana.PublicKey {
 	if m.Num > uint32(len(m.Products)) {
@@ -276,6 +783,33 @@ type PriceAccountEntry struct {
 	Slot   uint64           `json:"slot"`
 }
 
+// PriceAccountEntryJSON is a JSON representation of the prices of a PriceAccountEntry, see PriceAccountEntry.PriceJSON.
+type PriceAccountEntryJSON struct {
+	PriceAccountJSON
+	Pubkey solana.PublicKey `json:"pubkey"`
+	Slot   uint64           `json:"slot"`
+}
+
+// MarshalJSON encodes the price account as PriceAccount.MarshalJSON, along with its pubkey and slot.
+func (e PriceAccountEntry) MarshalJSON() ([]byte, error) {
+	fields, prices := priceAccountJSON(e.PriceAccount)
+	return json.Marshal(struct {
+		*priceAccountFields
+		Prices *PriceAccountJSON `json:",omitempty"`
+		Pubkey solana.PublicKey  `json:"pubkey"`
+		Slot   uint64            `json:"slot"`
+	}{fields, prices, e.Pubkey, e.Slot})
+}
+
+// PriceJSON returns the prices of the price account as PriceAccount.PriceJSON, along with its pubkey and slot.
+func (e PriceAccountEntry) PriceJSON() PriceAccountEntryJSON {
+	out := PriceAccountEntryJSON{Pubkey: e.Pubkey, Slot: e.Slot}
+	if e.PriceAccount != nil {
+		out.PriceAccountJSON = e.PriceAccount.PriceJSON()
+	}
+	return out
+}
+
 // MappingAccountEntry is a versioned mapping account and its pubkey.
 type MappingAccountEntry struct {
 	*MappingAccount
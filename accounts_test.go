@@ -17,7 +17,11 @@ package pyth
 import (
 	_ "embed"
 	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/stretchr/testify/assert"
@@ -107,7 +111,7 @@ var priceAccount_E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh = PriceAccount{
 		Size:        1200,
 	},
 	PriceType: 1,
-	Exponent:  -5,
+	Expo:      -5,
 	Num:       10,
 	NumQt:     0,
 	LastSlot:  117136050,
@@ -222,6 +226,409 @@ func TestPriceAccount(t *testing.T) {
 		comp := actual.GetComponent(&pubkey)
 		assert.Nil(t, comp)
 	})
+
+	t.Run("Exponent", func(t *testing.T) {
+		assert.Equal(t, int32(-5), actual.Exponent())
+	})
+
+	t.Run("ProductKey", func(t *testing.T) {
+		assert.Equal(t, solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko"), actual.ProductKey())
+	})
+
+	t.Run("CanPublish", func(t *testing.T) {
+		assert.True(t, actual.CanPublish(solana.MustPublicKeyFromBase58("EevTjv14eGHqsxKvgpastHsuLr9FNPfzkP23wG61pT2U")))
+		assert.False(t, actual.CanPublish(solana.StakeProgramID))
+		assert.False(t, actual.CanPublish(solana.PublicKey{}))
+	})
+
+	t.Run("ValidComponents", func(t *testing.T) {
+		assert.Equal(t, 32, actual.MaxComponents())
+		comps := actual.ValidComponents()
+		assert.Len(t, comps, 10)
+		assert.Equal(t, actual.Components[:10], comps)
+	})
+
+	t.Run("IsInitialized", func(t *testing.T) {
+		assert.True(t, actual.IsInitialized())
+
+		var fresh PriceAccount
+		assert.False(t, fresh.IsInitialized())
+		fresh.Version = V2
+		fresh.Num = 1
+		fresh.Components[0].Latest.PubSlot = 118774432
+		assert.True(t, fresh.IsInitialized())
+	})
+
+	t.Run("AgeSeconds", func(t *testing.T) {
+		slot := actual.Agg.PubSlot
+		assert.Equal(t, time.Duration(0), actual.AgeSeconds(slot, 0))
+		assert.Equal(t, time.Duration(0), actual.AgeSeconds(slot-1, 0))
+		assert.Equal(t, 4*time.Second, actual.AgeSeconds(slot+10, 0))
+		assert.Equal(t, 5*time.Second, actual.AgeSeconds(slot+10, 500*time.Millisecond))
+	})
+
+	t.Run("MinPublishers", func(t *testing.T) {
+		assert.Equal(t, uint8(0), actual.MinPublishers())
+
+		data := append([]byte{}, casePriceAccount...)
+		data[104] = 3 // low byte of Drv2
+		var acc PriceAccount
+		require.NoError(t, acc.UnmarshalBinary(data))
+		assert.Equal(t, uint8(3), acc.MinPublishers())
+	})
+}
+
+func TestPriceInfo_Value(t *testing.T) {
+	info := PriceInfo{Price: math.MaxInt64, Conf: math.MaxUint64, Status: PriceStatusTrading}
+	price, conf, ok := info.Value(12)
+	assert.True(t, ok)
+	assert.Equal(t, "9223372036854775807000000000000", price.String())
+	assert.Equal(t, "18446744073709551615000000000000", conf.String())
+
+	price, conf, _ = info.Value(-2)
+	assert.Equal(t, "92233720368547758.07", price.String())
+	assert.Equal(t, "184467440737095516.15", conf.String())
+}
+
+func TestPriceType(t *testing.T) {
+	assert.Equal(t, uint32(1), PriceTypeValue(PriceTypePrice))
+	typ, err := ParsePriceType(1)
+	require.NoError(t, err)
+	assert.Equal(t, PriceTypePrice, typ)
+	assert.Equal(t, "price", typ.String())
+	_, err = ParsePriceType(2)
+	assert.EqualError(t, err, "unsupported price type (2)")
+
+	var acc PriceAccount
+	require.NoError(t, acc.UnmarshalBinary(casePriceAccount))
+	typ, err = ParsePriceType(acc.PriceType)
+	require.NoError(t, err)
+	assert.Equal(t, PriceTypePrice, typ)
+}
+
+func TestParsePriceStatus(t *testing.T) {
+	for _, status := range []PriceStatus{PriceStatusUnknown, PriceStatusTrading, PriceStatusHalted, PriceStatusAuction} {
+		parsed, err := ParsePriceStatus(PriceStatusValue(status))
+		require.NoError(t, err)
+		assert.Equal(t, status, parsed)
+	}
+	assert.Equal(t, uint32(1), PriceStatusValue(PriceStatusTrading))
+	_, err := ParsePriceStatus(4)
+	assert.EqualError(t, err, "unsupported price status (4)")
+}
+
+func TestPriceStatus_IsUsable(t *testing.T) {
+	assert.Equal(t, PriceStatus(3), PriceStatusAuction)
+
+	assert.True(t, PriceStatusTrading.IsUsable(false))
+	assert.True(t, PriceStatusTrading.IsUsable(true))
+	assert.False(t, PriceStatusAuction.IsUsable(false))
+	assert.True(t, PriceStatusAuction.IsUsable(true))
+	assert.False(t, PriceStatusHalted.IsUsable(true))
+	assert.False(t, PriceStatusUnknown.IsUsable(true))
+}
+
+func TestPriceAccount_Derivations(t *testing.T) {
+	data := append([]byte{}, casePriceAccount...)
+	copy(data[96:112], []byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // drv1 = -1
+		0x05,       // min_pub = 5
+		0xfe,       // drv2 = -2
+		0x03, 0x00, // drv3 = 3
+		0xfc, 0xff, 0xff, 0xff, // drv4 = -4
+	})
+	copy(data[200:208], []byte{0x2a, 0, 0, 0, 0, 0, 0, 0}) // drv5 = 42
+
+	var acc PriceAccount
+	require.NoError(t, acc.UnmarshalBinary(data))
+	assert.Equal(t, []int64{-1, -2, 3, -4, 42}, acc.Derivations())
+	assert.Equal(t, uint8(5), acc.MinPublishers())
+}
+
+func TestPriceAccount_PublisherSlots(t *testing.T) {
+	var acc PriceAccount
+	require.NoError(t, acc.UnmarshalBinary(casePriceAccount))
+	slots := acc.PublisherSlots()
+	require.Len(t, slots, int(acc.Num))
+	for _, comp := range acc.ValidComponents() {
+		assert.Equal(t, comp.Latest.PubSlot, slots[comp.Publisher])
+	}
+	pub := solana.MustPublicKeyFromBase58("EevTjv14eGHqsxKvgpastHsuLr9FNPfzkP23wG61pT2U")
+	assert.Equal(t, acc.GetComponent(&pub).Latest.PubSlot, slots[pub])
+}
+
+func TestPriceAccount_SelfCheck(t *testing.T) {
+	var acc PriceAccount
+	require.NoError(t, acc.UnmarshalBinary(casePriceAccount))
+	assert.NoError(t, acc.SelfCheck())
+
+	tooMany := acc
+	tooMany.Num = 33
+	assert.EqualError(t, tooMany.SelfCheck(), "number of components 33 exceeds maximum of 32")
+
+	quoters := acc
+	quoters.NumQt = acc.Num + 1
+	assert.EqualError(t, quoters.SelfCheck(),
+		fmt.Sprintf("number of quoters %d exceeds number of components %d", acc.Num+1, acc.Num))
+
+	expo := acc
+	expo.Expo = -40
+	assert.ErrorIs(t, expo.SelfCheck(), ErrInvalidExponent)
+
+	stale := acc
+	stale.Components[acc.Num].Latest.Price = 1
+	assert.EqualError(t, stale.SelfCheck(),
+		fmt.Sprintf("component #%d past number of components %d is not empty", acc.Num, acc.Num))
+}
+
+func TestPriceAccount_JSON(t *testing.T) {
+	product := solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko")
+	publisher := solana.MustPublicKeyFromBase58("EevTjv14eGHqsxKvgpastHsuLr9FNPfzkP23wG61pT2U")
+	acc := &PriceAccount{
+		AccountHeader: AccountHeader{Version: V2},
+		Expo:          -8,
+		Num:           1,
+		Product:       product,
+		Agg:           PriceInfo{Price: math.MaxInt64, Conf: 120500000, Status: PriceStatusTrading, PubSlot: 100},
+	}
+	acc.Components[0] = PriceComp{
+		Publisher: publisher,
+		Latest:    PriceInfo{Price: -150, Conf: 2, Status: PriceStatusHalted, PubSlot: 99},
+	}
+
+	jsonData, err := json.Marshal(acc.PriceJSON())
+	require.NoError(t, err)
+	//language=JSON
+	expected := `{
+		"product": "EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko",
+		"next": "11111111111111111111111111111111",
+		"pub_slot": 100,
+		"agg": {"price": "92233720368.54775807", "conf": "1.205", "expo": -8, "status": "trading"},
+		"components": [{
+			"publisher": "EevTjv14eGHqsxKvgpastHsuLr9FNPfzkP23wG61pT2U",
+			"pub_slot": 99,
+			"latest": {"price": "-0.0000015", "conf": "0.00000002", "expo": -8, "status": "halted"}
+		}]
+	}`
+	assert.JSONEq(t, expected, string(jsonData))
+
+	entryData, err := json.Marshal(PriceAccountEntry{PriceAccount: acc, Pubkey: publisher, Slot: 101}.PriceJSON())
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(entryData, &entry))
+	assert.Equal(t, "EevTjv14eGHqsxKvgpastHsuLr9FNPfzkP23wG61pT2U", entry["pubkey"])
+	assert.Equal(t, float64(101), entry["slot"])
+	assert.Equal(t, "EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko", entry["product"])
+
+	// The full JSON encoding contains all fields alongside the decimal prices, and round-trips.
+	acc.Drv2 = 3
+	fullData, err := json.Marshal(acc)
+	require.NoError(t, err)
+	var decoded PriceAccount
+	require.NoError(t, json.Unmarshal(fullData, &decoded))
+	assert.Equal(t, acc, &decoded)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(fullData, &fields))
+	assert.JSONEq(t, "-8", string(fields["Exponent"]))
+	assert.JSONEq(t, "3", string(fields["Drv2"]))
+	assert.NotContains(t, fields, "Expo")
+	assert.JSONEq(t, expected, string(fields["Prices"]))
+
+	// Entries keep their pubkey and slot.
+	fullEntry := PriceAccountEntry{PriceAccount: acc, Pubkey: publisher, Slot: 101}
+	entryData, err = json.Marshal(fullEntry)
+	require.NoError(t, err)
+	var decodedEntry PriceAccountEntry
+	require.NoError(t, json.Unmarshal(entryData, &decodedEntry))
+	assert.Equal(t, fullEntry, decodedEntry)
+	fields = nil
+	require.NoError(t, json.Unmarshal(entryData, &fields))
+	assert.JSONEq(t, expected, string(fields["Prices"]))
+
+	update := KeyedPriceUpdate{PriceAccount: acc, Key: publisher, Slot: 101}
+	updateData, err := json.Marshal(update)
+	require.NoError(t, err)
+	var decodedUpdate KeyedPriceUpdate
+	require.NoError(t, json.Unmarshal(updateData, &decodedUpdate))
+	assert.Equal(t, update, decodedUpdate)
+}
+
+func TestPriceAccount_ConfidenceBps(t *testing.T) {
+	acc := PriceAccount{
+		Expo: -5,
+		Agg:  PriceInfo{Price: 200000, Conf: 50, Status: PriceStatusTrading},
+	}
+	bps, ok := acc.ConfidenceBps()
+	assert.True(t, ok)
+	assert.InDelta(t, 2.5, bps, 1e-9)
+
+	acc.Agg.Status = PriceStatusUnknown
+	_, ok = acc.ConfidenceBps()
+	assert.False(t, ok)
+
+	acc.Agg.Status = PriceStatusTrading
+	acc.Agg.Price = 0
+	_, ok = acc.ConfidenceBps()
+	assert.False(t, ok)
+}
+
+func TestPriceAccount_IsConfidenceAcceptable(t *testing.T) {
+	acc := PriceAccount{
+		Expo: -5,
+		Agg:  PriceInfo{Price: 200000, Conf: 50, Status: PriceStatusTrading},
+	}
+	assert.True(t, acc.IsConfidenceAcceptable(2.5))
+	assert.False(t, acc.IsConfidenceAcceptable(2))
+
+	acc.Agg.Status = PriceStatusHalted
+	assert.False(t, acc.IsConfidenceAcceptable(100))
+}
+
+func TestPriceAccount_IsLargeMove(t *testing.T) {
+	acc := PriceAccount{
+		Expo:      -5,
+		PrevPrice: 200000,
+		Agg:       PriceInfo{Price: 190000, Status: PriceStatusTrading},
+	}
+	large, bps := acc.IsLargeMove(400)
+	assert.True(t, large)
+	assert.InDelta(t, 500, bps, 1e-9)
+	large, bps = acc.IsLargeMove(500)
+	assert.False(t, large)
+	assert.InDelta(t, 500, bps, 1e-9)
+
+	acc.Agg.Status = PriceStatusHalted
+	large, bps = acc.IsLargeMove(0)
+	assert.False(t, large)
+	assert.Zero(t, bps)
+}
+
+func TestPriceAccount_PriceRat(t *testing.T) {
+	acc := PriceAccount{
+		Expo: -5,
+		Agg:  PriceInfo{Price: 200001, Status: PriceStatusTrading},
+	}
+	assert.Equal(t, "200001/100000", acc.PriceRat().String())
+
+	acc.Expo = 2
+	assert.Equal(t, "20000100/1", acc.PriceRat().String())
+
+	acc.Expo = -2
+	acc.Agg.Price = -150
+	assert.Equal(t, "-3/2", acc.PriceRat().String())
+}
+
+func TestPriceAccount_Cumulative(t *testing.T) {
+	var acc PriceAccount
+	require.NoError(t, acc.UnmarshalBinary(casePriceAccount))
+	assert.Nil(t, acc.Cumulative)
+
+	data := append([]byte{}, casePriceAccount...)
+	data = append(data, make([]byte, 48)...)
+	for i := 0; i < 16; i++ {
+		data[PriceAccountLen+i] = 0xff // price = -1
+	}
+	data[PriceAccountLen+16] = 0x2a // conf = 42
+	data[PriceAccountLen+32] = 0x07 // num_down = 7
+	require.NoError(t, acc.UnmarshalBinary(data))
+	require.NotNil(t, acc.Cumulative)
+	assert.Equal(t, big.NewInt(-1), acc.Cumulative.Price)
+	assert.Equal(t, big.NewInt(42), acc.Cumulative.Conf)
+	assert.Equal(t, uint64(7), acc.Cumulative.NumDown)
+
+	require.NoError(t, acc.UnmarshalBinary(casePriceAccount))
+	assert.Nil(t, acc.Cumulative)
+}
+
+func TestPriceAccount_TWAP(t *testing.T) {
+	prev := &PriceAccount{
+		Expo:       -2,
+		Agg:        PriceInfo{PubSlot: 100},
+		Cumulative: &PriceCumulative{Price: big.NewInt(50000), Conf: big.NewInt(0)},
+	}
+	cur := &PriceAccount{
+		Expo:       -2,
+		Agg:        PriceInfo{PubSlot: 110},
+		Cumulative: &PriceCumulative{Price: big.NewInt(51000), Conf: big.NewInt(0)},
+	}
+	twap, err := cur.TWAP(prev)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, twap, 1e-12)
+
+	_, err = prev.TWAP(cur)
+	assert.EqualError(t, err, "snapshot at slot 100 is not after previous at slot 110")
+
+	cur.Cumulative = nil
+	_, err = cur.TWAP(prev)
+	assert.EqualError(t, err, "price account has no cumulative fields")
+}
+
+func TestPriceAccount_PriceRounded(t *testing.T) {
+	acc := PriceAccount{
+		Expo: -5,
+		Agg:  PriceInfo{Price: 112725, Status: PriceStatusTrading},
+	}
+	price, ok := acc.PriceRounded(2)
+	assert.True(t, ok)
+	assert.Equal(t, 1.13, price)
+
+	acc.Agg.Price = 112625 // 1.12625 rounds to even
+	price, _ = acc.PriceRounded(4)
+	assert.Equal(t, 1.1262, price)
+
+	acc.Agg.Status = PriceStatusHalted
+	_, ok = acc.PriceRounded(2)
+	assert.False(t, ok)
+}
+
+func TestConfTracker(t *testing.T) {
+	var tracker ConfTracker
+	acc := PriceAccount{
+		Expo: -5,
+		Agg:  PriceInfo{Price: 200000, Conf: 50, Status: PriceStatusTrading},
+	}
+	assert.Equal(t, 0.0, tracker.Observe(&acc))
+
+	acc.Agg.Conf = 100
+	assert.InDelta(t, 2.5, tracker.Observe(&acc), 1e-9)
+
+	acc.Agg.Status = PriceStatusHalted
+	assert.Equal(t, 0.0, tracker.Observe(&acc))
+
+	acc.Agg.Status = PriceStatusTrading
+	acc.Agg.Conf = 40
+	assert.InDelta(t, -3.0, tracker.Observe(&acc), 1e-9)
+}
+
+func TestComparePrices(t *testing.T) {
+	a := &PriceAccount{
+		Expo: -5,
+		Agg:  PriceInfo{Price: 112717, Status: PriceStatusTrading},
+	}
+	b := &PriceAccount{
+		Expo: -3,
+		Agg:  PriceInfo{Price: 1127, Status: PriceStatusTrading},
+	}
+
+	cmp, err := ComparePrices(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cmp)
+
+	cmp, err = ComparePrices(b, a)
+	require.NoError(t, err)
+	assert.Equal(t, -1, cmp)
+
+	b.Agg.Price = 112717
+	b.Expo = -5
+	cmp, err = ComparePrices(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cmp)
+
+	b.Agg.Status = PriceStatusHalted
+	_, err = ComparePrices(a, b)
+	assert.EqualError(t, err, "second price is not trading")
+	_, err = ComparePrices(b, a)
+	assert.EqualError(t, err, "first price is not trading")
 }
 
 func TestMappingAccount(t *testing.T) {
@@ -307,4 +714,30 @@ func TestMappingAccount(t *testing.T) {
 	require.NoError(t, actual.UnmarshalBinary(caseMappingAccount))
 
 	assert.Equal(t, &expected, &actual)
+	assert.False(t, actual.IsFull())
+
+	actual.Num = uint32(len(actual.Products))
+	assert.True(t, actual.IsFull())
+}
+
+func TestParseAccount(t *testing.T) {
+	acc, err := ParseAccount(caseProductAccount)
+	require.NoError(t, err)
+	assert.IsType(t, &ProductAccount{}, acc)
+
+	acc, err = ParseAccount(casePriceAccount)
+	require.NoError(t, err)
+	assert.IsType(t, &PriceAccount{}, acc)
+
+	acc, err = ParseAccount(caseMappingAccount)
+	require.NoError(t, err)
+	assert.IsType(t, &MappingAccount{}, acc)
+
+	_, err = ParseAccount(nil)
+	assert.EqualError(t, err, "invalid account")
+
+	unknown := append([]byte{}, casePriceAccount...)
+	unknown[8] = 0x09
+	_, err = ParseAccount(unknown)
+	assert.EqualError(t, err, "unsupported account type (9)")
 }
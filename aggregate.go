@@ -0,0 +1,232 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// MaxSendLatency is the maximum number of slots a component price may lag behind
+// the aggregation slot to still be included in the aggregate.
+const MaxSendLatency = 25
+
+// SimulateAggregation predicts the effect of Instruction_AggPrice on a price account.
+//
+// Returns a copy of the account with the aggregate recomputed from the latest component prices
+// as the on-chain program would at the given slot. The provided account is not modified.
+// If currentSlot is not past the current aggregate publish slot, the copy is returned unchanged.
+// If fewer valid quotes than MinPublishers are available, the aggregate status becomes unknown.
+//
+// Please note that this function mirrors the on-chain algorithm on a "best effort" basis.
+// It does not update the TWAP and TWAC fields.
+func SimulateAggregation(acc *PriceAccount, currentSlot uint64) *PriceAccount {
+	out := new(PriceAccount)
+	*out = *acc
+
+	// Only re-compute aggregate in next slot.
+	if currentSlot <= out.Agg.PubSlot {
+		return out
+	}
+
+	// Remember previous aggregate.
+	out.PrevSlot = out.ValidSlot
+	out.PrevPrice = out.Agg.Price
+	out.PrevConf = out.Agg.Conf
+	out.ValidSlot = out.Agg.PubSlot
+	out.Agg.PubSlot = currentSlot
+
+	// Collect valid quotes.
+	numComps := int(out.Num)
+	if numComps > len(out.Components) {
+		numComps = len(out.Components)
+	}
+	var numValid uint32
+	prices := make([]int64, 0, 3*numComps)
+	for i := 0; i < numComps; i++ {
+		comp := &out.Components[i]
+		comp.Agg = comp.Latest
+		if !isValidQuote(&comp.Agg, currentSlot) {
+			continue
+		}
+		numValid++
+		price, conf := comp.Agg.Price, int64(comp.Agg.Conf)
+		prices = append(prices, price-conf, price, price+conf)
+	}
+
+	out.NumQt = numValid
+	if numValid == 0 || numValid < uint32(out.MinPublishers()) {
+		out.Agg.Status = PriceStatusUnknown
+		return out
+	}
+
+	p25, p50, p75 := quartiles(prices)
+	out.Agg.Status = PriceStatusTrading
+	out.Agg.Price = p50
+	out.Agg.Conf = uint64(p50 - p25)
+	if p75-p50 > p50-p25 {
+		out.Agg.Conf = uint64(p75 - p50)
+	}
+	out.LastSlot = currentSlot
+	return out
+}
+
+// ApplyUpdPrice applies the effect of Instruction_UpdPrice by a publisher to the price account in-place.
+//
+// Updates the latest price info of the publisher's component.
+// Returns an error if the publisher has no component in the price account,
+// or if the update is not newer than the publisher's latest price.
+//
+// Unlike the on-chain program, this does not aggregate prices in a new slot.
+// Use SimulateAggregation to recompute the aggregate after applying updates.
+func (p *PriceAccount) ApplyUpdPrice(publisher solana.PublicKey, cmd CommandUpdPrice) error {
+	numComps := int(p.Num)
+	if numComps > len(p.Components) {
+		numComps = len(p.Components)
+	}
+	for i := 0; i < numComps; i++ {
+		comp := &p.Components[i]
+		if comp.Publisher != publisher {
+			continue
+		}
+		if cmd.PubSlot <= comp.Latest.PubSlot {
+			return fmt.Errorf("publish slot %d is not newer than latest slot %d", cmd.PubSlot, comp.Latest.PubSlot)
+		}
+		comp.Latest.Price = cmd.Price
+		comp.Latest.Conf = cmd.Conf
+		comp.Latest.Status = cmd.Status
+		comp.Latest.PubSlot = cmd.PubSlot
+		return nil
+	}
+	return fmt.Errorf("publisher %s not found in price account", publisher)
+}
+
+// isValidQuote returns whether a component price is eligible for aggregation at the given slot.
+//
+// Negative prices are eligible, as long as the price plus or minus the confidence interval does not overflow.
+func isValidQuote(info *PriceInfo, slot uint64) bool {
+	if info.Status != PriceStatusTrading || info.Conf == 0 || info.Conf > math.MaxInt64 {
+		return false
+	}
+	conf := int64(info.Conf)
+	if info.Price < math.MinInt64+conf || info.Price > math.MaxInt64-conf {
+		return false
+	}
+	return slot >= info.PubSlot && slot-info.PubSlot <= MaxSendLatency
+}
+
+// quartiles sorts the given values in-place and returns the 25th, 50th and 75th percentiles.
+func quartiles(vals []int64) (p25, p50, p75 int64) {
+	sort.Slice(vals, func(i, j int) bool { return vals[i] < vals[j] })
+	n := len(vals)
+	i25 := n >> 2
+	i75 := n - 1 - i25
+	if n&2 != 0 || i25 == 0 {
+		p25, p75 = vals[i25], vals[i75]
+	} else {
+		p25 = avg(vals[i25-1], vals[i25])
+		p75 = avg(vals[i75], vals[i75+1])
+	}
+	if n&1 != 0 {
+		p50 = vals[n>>1]
+	} else {
+		p50 = avg(vals[(n>>1)-1], vals[n>>1])
+	}
+	return
+}
+
+// avg returns the average of two integers without overflowing.
+func avg(a, b int64) int64 {
+	return a/2 + b/2 + (a%2+b%2)/2
+}
+
+// CombinePrices computes a confidence-weighted mean of the aggregate prices of multiple feeds.
+//
+// Each trading feed is weighted by the inverse of its squared confidence interval (inverse-variance weighting)
+// after applying its exponent. The returned confidence is the standard error of the weighted mean.
+// Feeds that are not trading or have a zero confidence interval are ignored,
+// as are feeds whose values overflow a float64 after applying their exponent.
+// If maxBps is positive, feeds with a confidence interval above maxBps basis points are ignored,
+// see PriceAccount.IsConfidenceAcceptable.
+// If ok is false, none of the feeds were usable.
+func CombinePrices(accs []*PriceAccount, maxBps float64) (price float64, conf float64, ok bool) {
+	var sumWeights, sumWeighted float64
+	for _, acc := range accs {
+		if acc == nil || acc.Agg.Status != PriceStatusTrading || acc.Agg.Conf == 0 {
+			continue
+		}
+		if maxBps > 0 && !acc.IsConfidenceAcceptable(maxBps) {
+			continue
+		}
+		scale := math.Pow10(int(acc.Exponent()))
+		p := float64(acc.Agg.Price) * scale
+		c := float64(acc.Agg.Conf) * scale
+		if math.IsInf(p, 0) || math.IsInf(c*c, 0) {
+			continue // overflowed after applying exponent
+		}
+		w := 1 / (c * c)
+		sumWeights += w
+		sumWeighted += w * p
+	}
+	if sumWeights == 0 || math.IsInf(sumWeights, 0) {
+		return 0, 0, false
+	}
+	return sumWeighted / sumWeights, math.Sqrt(1 / sumWeights), true
+}
+
+// Aggregate is a snapshot of the aggregate price of a price account and its metadata.
+type Aggregate struct {
+	Price      int64       // aggregate price
+	Conf       uint64      // aggregate confidence interval
+	Status     PriceStatus // status of the aggregate price
+	PubSlot    uint64      // slot of the aggregate price
+	NumQuoters uint32      // number of quoters that make up the aggregate
+	Exponent   int32       // exponent of price and confidence interval
+}
+
+// Aggregate returns a snapshot of the aggregate price of the price account.
+func (p *PriceAccount) Aggregate() Aggregate {
+	return Aggregate{
+		Price:      p.Agg.Price,
+		Conf:       p.Agg.Conf,
+		Status:     p.Agg.Status,
+		PubSlot:    p.Agg.PubSlot,
+		NumQuoters: p.NumQt,
+		Exponent:   p.Exponent(),
+	}
+}
+
+// ComponentDeviations returns the signed relative deviation of each active publisher's price from the aggregate price.
+//
+// A publisher is active if its price used for aggregation is trading.
+// The deviation is (component - aggregate) / aggregate, e.g. 0.01 for a price 1% above the aggregate.
+// Returns nil if the aggregate price is zero or not trading.
+func (p *PriceAccount) ComponentDeviations() map[solana.PublicKey]float64 {
+	if p.Agg.Price == 0 || p.Agg.Status != PriceStatusTrading {
+		return nil
+	}
+	agg := float64(p.Agg.Price)
+	devs := make(map[solana.PublicKey]float64)
+	for _, comp := range p.ValidComponents() {
+		if comp.Agg.Status != PriceStatusTrading {
+			continue
+		}
+		devs[comp.Publisher] = (float64(comp.Agg.Price) - agg) / math.Abs(agg)
+	}
+	return devs
+}
@@ -0,0 +1,174 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateAggregation(t *testing.T) {
+	acc := PriceAccount{
+		Num:       3,
+		ValidSlot: 90,
+		Agg: PriceInfo{
+			Price:   100,
+			Conf:    5,
+			Status:  PriceStatusTrading,
+			PubSlot: 99,
+		},
+	}
+	acc.Components[0].Latest = PriceInfo{Price: 100, Conf: 2, Status: PriceStatusTrading, PubSlot: 98}
+	acc.Components[1].Latest = PriceInfo{Price: 104, Conf: 2, Status: PriceStatusTrading, PubSlot: 99}
+	acc.Components[2].Latest = PriceInfo{Price: 500, Conf: 1, Status: PriceStatusHalted, PubSlot: 99}
+
+	out := SimulateAggregation(&acc, 100)
+
+	assert.Equal(t, uint64(99), acc.Agg.PubSlot, "input must not be modified")
+	assert.Equal(t, uint32(2), out.NumQt)
+	assert.Equal(t, PriceInfo{Price: 102, Conf: 2, Status: PriceStatusTrading, PubSlot: 100}, out.Agg)
+	assert.Equal(t, uint64(100), out.LastSlot)
+	assert.Equal(t, uint64(99), out.ValidSlot)
+	assert.Equal(t, uint64(90), out.PrevSlot)
+	assert.Equal(t, int64(100), out.PrevPrice)
+	assert.Equal(t, uint64(5), out.PrevConf)
+	assert.Equal(t, acc.Components[2].Latest, out.Components[2].Agg)
+
+	t.Run("SameSlot", func(t *testing.T) {
+		assert.Equal(t, &acc, SimulateAggregation(&acc, 99))
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		out := SimulateAggregation(&acc, 99+MaxSendLatency+1)
+		assert.Equal(t, uint32(0), out.NumQt)
+		assert.Equal(t, PriceStatusUnknown, out.Agg.Status)
+	})
+
+	t.Run("BelowMinPub", func(t *testing.T) {
+		minPub := acc
+		minPub.Drv2 = 3
+		out := SimulateAggregation(&minPub, 100)
+		assert.Equal(t, uint32(2), out.NumQt)
+		assert.Equal(t, PriceStatusUnknown, out.Agg.Status)
+		assert.Equal(t, int64(100), out.Agg.Price)
+		assert.Equal(t, uint64(0), out.LastSlot)
+
+		minPub.Drv2 = 2
+		out = SimulateAggregation(&minPub, 100)
+		assert.Equal(t, PriceStatusTrading, out.Agg.Status)
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+		negative := acc
+		negative.Components[0].Latest.Price = -100
+		negative.Components[1].Latest.Price = -104
+		out := SimulateAggregation(&negative, 100)
+		assert.Equal(t, uint32(2), out.NumQt)
+		assert.Equal(t, PriceInfo{Price: -102, Conf: 2, Status: PriceStatusTrading, PubSlot: 100}, out.Agg)
+	})
+}
+
+func TestPriceAccount_ApplyUpdPrice(t *testing.T) {
+	pub1 := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	pub2 := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	var acc PriceAccount
+	acc.Num = 2
+	acc.Components[0].Publisher = pub1
+	acc.Components[1].Publisher = pub2
+	acc.Components[1].Latest = PriceInfo{Price: 104, Conf: 2, Status: PriceStatusTrading, PubSlot: 99}
+
+	require.NoError(t, acc.ApplyUpdPrice(pub1, CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Price:   100,
+		Conf:    2,
+		PubSlot: 99,
+	}))
+	assert.Equal(t, PriceInfo{Price: 100, Conf: 2, Status: PriceStatusTrading, PubSlot: 99}, acc.Components[0].Latest)
+
+	out := SimulateAggregation(&acc, 100)
+	assert.Equal(t, uint32(2), out.NumQt)
+	assert.Equal(t, int64(102), out.Agg.Price)
+
+	err := acc.ApplyUpdPrice(pub2, CommandUpdPrice{Status: PriceStatusTrading, Price: 1, Conf: 1, PubSlot: 99})
+	assert.EqualError(t, err, "publish slot 99 is not newer than latest slot 99")
+	err = acc.ApplyUpdPrice(solana.SysVarClockPubkey, CommandUpdPrice{PubSlot: 100})
+	assert.EqualError(t, err, "publisher SysvarC1ock11111111111111111111111111111111 not found in price account")
+}
+
+func TestCombinePrices(t *testing.T) {
+	feeds := []*PriceAccount{
+		{Expo: -2, Agg: PriceInfo{Price: 10000, Conf: 100, Status: PriceStatusTrading}},
+		{Expo: -1, Agg: PriceInfo{Price: 1020, Conf: 20, Status: PriceStatusTrading}},
+		{Expo: -2, Agg: PriceInfo{Price: 50000, Conf: 1, Status: PriceStatusHalted}},
+		{Expo: -2, Agg: PriceInfo{Price: 50000, Conf: 0, Status: PriceStatusTrading}},
+		{Expo: 300, Agg: PriceInfo{Price: math.MaxInt64, Conf: 1, Status: PriceStatusTrading}},
+		nil,
+	}
+	price, conf, ok := CombinePrices(feeds, 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 100.4, price, 1e-9)
+	assert.InDelta(t, 0.894427191, conf, 1e-9)
+
+	// Second feed has a confidence of 196 bps and is dropped.
+	price, conf, ok = CombinePrices(feeds, 150)
+	assert.True(t, ok)
+	assert.InDelta(t, 100, price, 1e-9)
+	assert.InDelta(t, 1, conf, 1e-9)
+	_, _, ok = CombinePrices(feeds, 50)
+	assert.False(t, ok)
+
+	_, _, ok = CombinePrices(feeds[2:], 0)
+	assert.False(t, ok)
+	_, _, ok = CombinePrices(nil, 0)
+	assert.False(t, ok)
+}
+
+func TestPriceAccount_Aggregate(t *testing.T) {
+	var acc PriceAccount
+	require.NoError(t, acc.UnmarshalBinary(casePriceAccount))
+	assert.Equal(t, Aggregate{
+		Price:      acc.Agg.Price,
+		Conf:       acc.Agg.Conf,
+		Status:     acc.Agg.Status,
+		PubSlot:    acc.Agg.PubSlot,
+		NumQuoters: acc.NumQt,
+		Exponent:   -5,
+	}, acc.Aggregate())
+}
+
+func TestPriceAccount_ComponentDeviations(t *testing.T) {
+	pub1 := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	pub2 := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	pub3 := solana.MustPublicKeyFromBase58("EevTjv14eGHqsxKvgpastHsuLr9FNPfzkP23wG61pT2U")
+	var acc PriceAccount
+	acc.Version = V2
+	acc.Num = 3
+	acc.Agg = PriceInfo{Price: 200, Status: PriceStatusTrading}
+	acc.Components[0] = PriceComp{Publisher: pub1, Agg: PriceInfo{Price: 202, Status: PriceStatusTrading}}
+	acc.Components[1] = PriceComp{Publisher: pub2, Agg: PriceInfo{Price: 190, Status: PriceStatusTrading}}
+	acc.Components[2] = PriceComp{Publisher: pub3, Agg: PriceInfo{Price: 500, Status: PriceStatusHalted}}
+
+	devs := acc.ComponentDeviations()
+	require.Len(t, devs, 2)
+	assert.InDelta(t, 0.01, devs[pub1], 1e-9)
+	assert.InDelta(t, -0.05, devs[pub2], 1e-9)
+
+	acc.Agg.Status = PriceStatusUnknown
+	assert.Nil(t, acc.ComponentDeviations())
+}
@@ -54,13 +54,69 @@ func (a AttrsMap) KVs() map[string]string {
 	return m
 }
 
-// Sort sorts the keys of an AttrsMap by lexicographic order.
+// Sort sorts the pairs of an AttrsMap by lexicographic order of their keys.
+//
+// Pairs with duplicate keys are ordered by value.
+// Sorting yields a canonical order, such that equal attributes always result in the same binary encoding,
+// regardless of their original order.
 func (a AttrsMap) Sort() {
 	sort.Slice(a.Pairs, func(i, j int) bool {
-		return strings.Compare(a.Pairs[i][0], a.Pairs[j][0]) < 0
+		if c := strings.Compare(a.Pairs[i][0], a.Pairs[j][0]); c != 0 {
+			return c < 0
+		}
+		return strings.Compare(a.Pairs[i][1], a.Pairs[j][1]) < 0
 	})
 }
 
+// EqualIgnoring returns whether two AttrsMaps contain the same pairs, regardless of order,
+// excluding any pairs with one of the given keys.
+func (a AttrsMap) EqualIgnoring(other AttrsMap, ignore ...string) bool {
+	filter := func(pairs [][2]string) AttrsMap {
+		var out AttrsMap
+	outer:
+		for _, kv := range pairs {
+			for _, key := range ignore {
+				if kv[0] == key {
+					continue outer
+				}
+			}
+			out.Pairs = append(out.Pairs, kv)
+		}
+		out.Sort()
+		return out
+	}
+	x, y := filter(a.Pairs), filter(other.Pairs)
+	if len(x.Pairs) != len(y.Pairs) {
+		return false
+	}
+	for i := range x.Pairs {
+		if x.Pairs[i] != y.Pairs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the pairs of an AttrsMap as aligned columns, one pair per line.
+//
+// Keys are padded to the width of the longest key.
+func (a AttrsMap) String() string {
+	var width int
+	for _, kv := range a.Pairs {
+		if len(kv[0]) > width {
+			width = len(kv[0])
+		}
+	}
+	var sb strings.Builder
+	for i, kv := range a.Pairs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "%-*s  %s", width, kv[0], kv[1])
+	}
+	return sb.String()
+}
+
 // UnmarshalBinary unmarshals AttrsMap from its on-chain format.
 //
 // Will return an error if it fails to consume the entire provided byte slice.
@@ -77,6 +133,13 @@ func (a AttrsMap) BinaryLen() (size int) {
 	return
 }
 
+// ByteSize returns the exact serialized size of the attributes, equivalent to BinaryLen.
+//
+// Attributes of a product account fit into at most ProductAccountAttrsLen bytes.
+func (a AttrsMap) ByteSize() int {
+	return a.BinaryLen()
+}
+
 // ReadAttrsMapFromBinary consumes all bytes from a binary reader,
 // returning an AttrsMap and the number of bytes read.
 func ReadAttrsMapFromBinary(rd *bytes.Reader) (out AttrsMap, n int, err error) {
@@ -47,6 +47,75 @@ func TestAttrsMap(t *testing.T) {
 	assert.Equal(t, attrs, attrs2)
 }
 
+func TestAttrsMap_Sort(t *testing.T) {
+	a := AttrsMap{Pairs: [][2]string{
+		{"symbol", "FX.EUR/USD"},
+		{"base", "EUR"},
+		{"asset_type", "FX"},
+		{"base", "USD"},
+	}}
+	b := AttrsMap{Pairs: [][2]string{
+		{"base", "USD"},
+		{"asset_type", "FX"},
+		{"base", "EUR"},
+		{"symbol", "FX.EUR/USD"},
+	}}
+	a.Sort()
+	b.Sort()
+	assert.Equal(t, [][2]string{
+		{"asset_type", "FX"},
+		{"base", "EUR"},
+		{"base", "USD"},
+		{"symbol", "FX.EUR/USD"},
+	}, a.Pairs)
+	assert.Equal(t, a, b)
+}
+
+func TestAttrsMap_String(t *testing.T) {
+	a := AttrsMap{Pairs: [][2]string{
+		{"asset_type", "FX"},
+		{"base", "EUR"},
+		{"symbol", "FX.EUR/USD"},
+	}}
+	assert.Equal(t, ""+
+		"asset_type  FX\n"+
+		"base        EUR\n"+
+		"symbol      FX.EUR/USD", a.String())
+	assert.Equal(t, "", AttrsMap{}.String())
+}
+
+func TestAttrsMap_EqualIgnoring(t *testing.T) {
+	a := AttrsMap{Pairs: [][2]string{
+		{"symbol", "FX.EUR/USD"},
+		{"updated_at", "1650000000"},
+		{"base", "EUR"},
+	}}
+	b := AttrsMap{Pairs: [][2]string{
+		{"base", "EUR"},
+		{"symbol", "FX.EUR/USD"},
+		{"updated_at", "1650000400"},
+	}}
+	assert.False(t, a.EqualIgnoring(b))
+	assert.True(t, a.EqualIgnoring(b, "updated_at"))
+	assert.True(t, a.EqualIgnoring(b, "nonce", "updated_at"))
+	assert.False(t, a.EqualIgnoring(b, "base"))
+	assert.True(t, AttrsMap{}.EqualIgnoring(AttrsMap{Pairs: [][2]string{{"nonce", "1"}}}, "nonce"))
+	// Input order must be preserved.
+	assert.Equal(t, "symbol", a.Pairs[0][0])
+}
+
+func TestAttrsMap_ByteSize(t *testing.T) {
+	a := AttrsMap{Pairs: [][2]string{
+		{"base", "EUR"},
+		{"symbol", "FX.EUR/USD"},
+	}}
+	data, err := a.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, 1+4+1+3+1+6+1+10, a.ByteSize())
+	assert.Len(t, data, a.ByteSize())
+	assert.Equal(t, 0, AttrsMap{}.ByteSize())
+}
+
 func TestAttrsMap_LongKey(t *testing.T) {
 	longKey := strings.Repeat("A", 256)
 	caseMap := map[string]string{
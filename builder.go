@@ -14,7 +14,34 @@
 
 package pyth
 
-import "github.com/gagliardetto/solana-go"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Exponent bounds of price accounts.
+//
+// Price accounts with an exponent outside these bounds are not usable.
+const (
+	MinExponent = -12
+	MaxExponent = 12
+)
+
+// ErrInvalidExponent is returned when building an instruction with an out-of-range price exponent.
+var ErrInvalidExponent = errors.New("invalid exponent")
+
+// validateExponent checks whether an exponent is within MinExponent and MaxExponent.
+func validateExponent(exponent int32) error {
+	if exponent < MinExponent {
+		return fmt.Errorf("%w %d: below minimum of %d", ErrInvalidExponent, exponent, MinExponent)
+	}
+	if exponent > MaxExponent {
+		return fmt.Errorf("%w %d: above maximum of %d", ErrInvalidExponent, exponent, MaxExponent)
+	}
+	return nil
+}
 
 // InstructionBuilder creates new instructions to interact with the Pyth on-chain program.
 type InstructionBuilder struct {
@@ -76,6 +103,9 @@ func (i *InstructionBuilder) AddProduct(
 }
 
 // UpdProduct updates a product account.
+//
+// Attributes are encoded in the order of payload.Pairs.
+// Call AttrsMap.Sort beforehand to get byte-identical instructions given the same attributes.
 func (i *InstructionBuilder) UpdProduct(
 	fundingKey solana.PublicKey,
 	productKey solana.PublicKey,
@@ -92,6 +122,13 @@ func (i *InstructionBuilder) UpdProduct(
 	}
 }
 
+// ClearProduct updates a product account, removing all of its attributes.
+//
+// The instruction data consists of the command header only, as an empty AttrsMap has zero length.
+func (i *InstructionBuilder) ClearProduct(fundingKey solana.PublicKey, productKey solana.PublicKey) *Instruction {
+	return i.UpdProduct(fundingKey, productKey, CommandUpdProduct{})
+}
+
 // AddPrice adds a new price account to a product account.
 func (i *InstructionBuilder) AddPrice(
 	fundingKey solana.PublicKey,
@@ -111,6 +148,19 @@ func (i *InstructionBuilder) AddPrice(
 	}
 }
 
+// TryAddPrice is like AddPrice, but returns an error if the exponent is out of range.
+func (i *InstructionBuilder) TryAddPrice(
+	fundingKey solana.PublicKey,
+	productKey solana.PublicKey,
+	priceKey solana.PublicKey,
+	payload CommandAddPrice,
+) (*Instruction, error) {
+	if err := validateExponent(payload.Exponent); err != nil {
+		return nil, err
+	}
+	return i.AddPrice(fundingKey, productKey, priceKey, payload), nil
+}
+
 // AddPublisher adds a publisher to a price account.
 func (i *InstructionBuilder) AddPublisher(
 	fundingKey solana.PublicKey,
@@ -128,6 +178,32 @@ func (i *InstructionBuilder) AddPublisher(
 	}
 }
 
+// AddPublishers adds multiple publishers to a price account, one instruction per publisher.
+//
+// Returns an error if pubs contains duplicate keys.
+// If current is not nil, also returns an error if a publisher already has a component slot in current,
+// which should be the current state of the price account.
+func (i *InstructionBuilder) AddPublishers(
+	fundingKey solana.PublicKey,
+	priceKey solana.PublicKey,
+	pubs []solana.PublicKey,
+	current *PriceAccount,
+) ([]*Instruction, error) {
+	insts := make([]*Instruction, 0, len(pubs))
+	seen := make(map[solana.PublicKey]struct{}, len(pubs))
+	for _, pub := range pubs {
+		if _, ok := seen[pub]; ok {
+			return nil, fmt.Errorf("duplicate publisher %s", pub)
+		}
+		seen[pub] = struct{}{}
+		if current != nil && current.CanPublish(pub) {
+			return nil, fmt.Errorf("publisher %s already added to price account", pub)
+		}
+		insts = append(insts, i.AddPublisher(fundingKey, priceKey, CommandAddPublisher{Publisher: pub}))
+	}
+	return insts, nil
+}
+
 // DelPublisher deletes a publisher from a price account.
 func (i *InstructionBuilder) DelPublisher(
 	fundingKey solana.PublicKey,
@@ -163,6 +239,79 @@ func (i *InstructionBuilder) UpdPrice(
 	}
 }
 
+// TryUpdPrice is like UpdPrice, but returns an error if the payload is invalid.
+//
+// See CommandUpdPrice.Validate.
+func (i *InstructionBuilder) TryUpdPrice(
+	fundingKey solana.PublicKey,
+	priceKey solana.PublicKey,
+	payload CommandUpdPrice,
+	opts ...ValidateOption,
+) (*Instruction, error) {
+	if err := payload.Validate(opts...); err != nil {
+		return nil, err
+	}
+	return i.UpdPrice(fundingKey, priceKey, payload), nil
+}
+
+// computeBudgetProgramID is the program ID of the native compute budget program.
+var computeBudgetProgramID = solana.MustPublicKeyFromBase58("ComputeBudget111111111111111111111111111111")
+
+// computeBudgetSetComputeUnitPrice is the instruction type of SetComputeUnitPrice in the compute budget program.
+const computeBudgetSetComputeUnitPrice = 3
+
+// UpdPriceWithPriority is like UpdPrice, but prepends a compute budget instruction
+// setting the compute unit price to the given number of micro-lamports as priority fee.
+//
+// Returns solana.Instruction values because the compute budget instruction is not a Pyth instruction.
+func (i *InstructionBuilder) UpdPriceWithPriority(
+	fundingKey solana.PublicKey,
+	priceKey solana.PublicKey,
+	payload CommandUpdPrice,
+	microLamports uint64,
+) []solana.Instruction {
+	data := appendUint64([]byte{computeBudgetSetComputeUnitPrice}, microLamports)
+	return []solana.Instruction{
+		solana.NewInstruction(computeBudgetProgramID, solana.AccountMetaSlice{}, data),
+		i.UpdPrice(fundingKey, priceKey, payload),
+	}
+}
+
+// UpdPriceNoFailOnError publishes a new component price to a price account.
+//
+// Unlike UpdPrice, the transaction does not fail if the update cannot be applied.
+func (i *InstructionBuilder) UpdPriceNoFailOnError(
+	fundingKey solana.PublicKey,
+	priceKey solana.PublicKey,
+	payload CommandUpdPrice,
+) *Instruction {
+	return &Instruction{
+		programKey: i.programKey,
+		Header:     makeCommandHeader(Instruction_UpdPriceNoFailOnError),
+		accounts: []*solana.AccountMeta{
+			solana.Meta(fundingKey).SIGNER().WRITE(),
+			solana.Meta(priceKey).WRITE(),
+			solana.Meta(solana.SysVarClockPubkey),
+		},
+		Payload: &payload,
+	}
+}
+
+// TryUpdPriceNoFailOnError is like UpdPriceNoFailOnError, but returns an error if the payload is invalid.
+//
+// See CommandUpdPrice.Validate.
+func (i *InstructionBuilder) TryUpdPriceNoFailOnError(
+	fundingKey solana.PublicKey,
+	priceKey solana.PublicKey,
+	payload CommandUpdPrice,
+	opts ...ValidateOption,
+) (*Instruction, error) {
+	if err := payload.Validate(opts...); err != nil {
+		return nil, err
+	}
+	return i.UpdPriceNoFailOnError(fundingKey, priceKey, payload), nil
+}
+
 // AggPrice computes the aggregate price for a product account.
 func (i *InstructionBuilder) AggPrice(
 	fundingKey solana.PublicKey,
@@ -196,6 +345,18 @@ func (i *InstructionBuilder) InitPrice(
 	}
 }
 
+// TryInitPrice is like InitPrice, but returns an error if the exponent is out of range.
+func (i *InstructionBuilder) TryInitPrice(
+	fundingKey solana.PublicKey,
+	priceKey solana.PublicKey,
+	payload CommandInitPrice,
+) (*Instruction, error) {
+	if err := validateExponent(payload.Exponent); err != nil {
+		return nil, err
+	}
+	return i.InitPrice(fundingKey, priceKey, payload), nil
+}
+
 // InitTest initializes a test account.
 func (i *InstructionBuilder) InitTest(
 	fundingKey solana.PublicKey,
@@ -244,3 +405,83 @@ func (i *InstructionBuilder) SetMinPub(
 		Payload: &payload,
 	}
 }
+
+// accountRole describes the permissions of an instruction account.
+type accountRole struct {
+	signer   bool
+	writable bool
+}
+
+var (
+	roleSigner   = accountRole{signer: true, writable: true}
+	roleWritable = accountRole{writable: true}
+	roleReadOnly = accountRole{}
+)
+
+// accountRoles lists the canonical account permissions of each instruction type, in order.
+var accountRoles = map[int32][]accountRole{
+	Instruction_InitMapping:           {roleSigner, roleSigner},
+	Instruction_AddMapping:            {roleSigner, roleSigner, roleSigner},
+	Instruction_AddProduct:            {roleSigner, roleSigner, roleSigner},
+	Instruction_UpdProduct:            {roleSigner, roleSigner},
+	Instruction_AddPrice:              {roleSigner, roleSigner, roleSigner},
+	Instruction_AddPublisher:          {roleSigner, roleSigner},
+	Instruction_DelPublisher:          {roleSigner, roleSigner},
+	Instruction_UpdPrice:              {roleSigner, roleWritable, roleReadOnly},
+	Instruction_AggPrice:              {roleSigner, roleWritable, roleReadOnly},
+	Instruction_InitPrice:             {roleSigner, roleSigner},
+	Instruction_InitTest:              {roleSigner, roleSigner},
+	Instruction_UpdTest:               {roleSigner, roleSigner},
+	Instruction_SetMinPub:             {roleSigner, roleSigner},
+	Instruction_UpdPriceNoFailOnError: {roleSigner, roleWritable, roleReadOnly},
+}
+
+// accountLabels lists the canonical account names of each instruction type, in order.
+var accountLabels = map[int32][]string{
+	Instruction_InitMapping:           {"funding", "mapping"},
+	Instruction_AddMapping:            {"funding", "tail_mapping", "new_mapping"},
+	Instruction_AddProduct:            {"funding", "mapping", "product"},
+	Instruction_UpdProduct:            {"funding", "product"},
+	Instruction_AddPrice:              {"funding", "product", "price"},
+	Instruction_AddPublisher:          {"funding", "price"},
+	Instruction_DelPublisher:          {"funding", "price"},
+	Instruction_UpdPrice:              {"funding", "price", "clock"},
+	Instruction_AggPrice:              {"funding", "price", "clock"},
+	Instruction_InitPrice:             {"funding", "price"},
+	Instruction_InitTest:              {"funding", "test"},
+	Instruction_UpdTest:               {"funding", "test"},
+	Instruction_SetMinPub:             {"funding", "price"},
+	Instruction_UpdPriceNoFailOnError: {"funding", "price", "clock"},
+}
+
+// AccountLabels returns the role name of each account of an instruction type, in order.
+//
+// Returns nil if the instruction type is not supported.
+func AccountLabels(cmd int32) []string {
+	labels, ok := accountLabels[cmd]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), labels...)
+}
+
+// AccountsFor returns the account metas of an instruction type given its account keys.
+//
+// Keys are expected in the same order as accepted by the respective InstructionBuilder method,
+// including sysvar accounts (e.g. the clock account of upd_price).
+// The signer and writable flags are set as required by the on-chain program.
+func AccountsFor(cmd int32, keys ...solana.PublicKey) ([]*solana.AccountMeta, error) {
+	roles, ok := accountRoles[cmd]
+	if !ok {
+		return nil, fmt.Errorf("unsupported instruction type (%d)", cmd)
+	}
+	if len(keys) != len(roles) {
+		return nil, fmt.Errorf("expected %d accounts for %s but got %d",
+			len(roles), InstructionIDToName(cmd), len(keys))
+	}
+	accounts := make([]*solana.AccountMeta, len(keys))
+	for i, key := range keys {
+		accounts[i] = solana.NewAccountMeta(key, roles[i].writable, roles[i].signer)
+	}
+	return accounts, nil
+}
@@ -0,0 +1,143 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"reflect"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// CachedDecoder wraps DecodeInstruction with a least-recently-used cache of decoded instructions.
+//
+// Do not instantiate CachedDecoder directly, use NewCachedDecoder instead.
+// It is safe for concurrent use.
+type CachedDecoder struct {
+	size int
+
+	lock    sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // front is most recently used
+}
+
+type cachedDecodeResult struct {
+	key  [sha256.Size]byte
+	inst *Instruction
+	err  error
+}
+
+// NewCachedDecoder creates a new decoder caching up to size results.
+func NewCachedDecoder(size int) *CachedDecoder {
+	if size < 1 {
+		size = 1
+	}
+	return &CachedDecoder{
+		size:    size,
+		entries: make(map[[sha256.Size]byte]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// Decode behaves like DecodeInstruction, but returns a cached result if the same input was seen recently.
+//
+// The returned instruction, including its account metas and payload, is a copy owned by the caller.
+func (c *CachedDecoder) Decode(
+	programKey solana.PublicKey,
+	accounts []*solana.AccountMeta,
+	data []byte,
+) (*Instruction, error) {
+	key := decodeCacheKey(programKey, accounts, data)
+
+	c.lock.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		res := elem.Value.(*cachedDecodeResult)
+		c.lock.Unlock()
+		return copyInstruction(res.inst), res.err
+	}
+	c.lock.Unlock()
+
+	inst, err := DecodeInstruction(programKey, accounts, data)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.entries[key] = c.order.PushFront(&cachedDecodeResult{key: key, inst: copyInstruction(inst), err: err})
+		for c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cachedDecodeResult).key)
+		}
+	}
+	return inst, err
+}
+
+// copyInstruction returns a deep copy of the instruction that shares no memory with the original.
+func copyInstruction(inst *Instruction) *Instruction {
+	if inst == nil {
+		return nil
+	}
+	cpy := *inst
+	cpy.accounts = make(solana.AccountMetaSlice, len(inst.accounts))
+	for i, acc := range inst.accounts {
+		meta := *acc
+		cpy.accounts[i] = &meta
+	}
+	cpy.Payload = copyPayload(inst.Payload)
+	return &cpy
+}
+
+// copyPayload returns a deep copy of an instruction payload as returned by DecodeInstruction.
+//
+// All payload types are plain value structs, except for the attribute pairs of CommandUpdProduct.
+func copyPayload(payload interface{}) interface{} {
+	if p, ok := payload.(*CommandUpdProduct); ok && p != nil {
+		return &CommandUpdProduct{AttrsMap{Pairs: append([][2]string(nil), p.Pairs...)}}
+	}
+	v := reflect.ValueOf(payload)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return payload
+	}
+	cpy := reflect.New(v.Elem().Type())
+	cpy.Elem().Set(v.Elem())
+	return cpy.Interface()
+}
+
+// Len returns the number of cached results.
+func (c *CachedDecoder) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
+
+// decodeCacheKey hashes all inputs of DecodeInstruction.
+func decodeCacheKey(programKey solana.PublicKey, accounts []*solana.AccountMeta, data []byte) (key [sha256.Size]byte) {
+	h := sha256.New()
+	h.Write(programKey[:])
+	var numAccounts [8]byte
+	binary.LittleEndian.PutUint64(numAccounts[:], uint64(len(accounts)))
+	h.Write(numAccounts[:])
+	for _, acc := range accounts {
+		h.Write(acc.PublicKey[:])
+		h.Write([]byte{accountMetaFlags(acc)})
+	}
+	h.Write(data)
+	copy(key[:], h.Sum(nil))
+	return
+}
@@ -0,0 +1,123 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedDecoder(t *testing.T) {
+	var env = Devnet
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")).SIGNER().WRITE(),
+	}
+
+	dec := NewCachedDecoder(2)
+
+	ins1, err := dec.Decode(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+	expected, err := DecodeInstruction(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+	assert.Equal(t, expected, ins1)
+
+	ins2, err := dec.Decode(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+	assert.Equal(t, ins1, ins2, "expected cache hit")
+	assert.NotSame(t, ins1, ins2, "expected copy of cached instruction")
+	assert.Equal(t, 1, dec.Len())
+
+	// Different account flags must not hit the cache.
+	readOnly := []*solana.AccountMeta{accs[0], solana.Meta(accs[1].PublicKey)}
+	ins3, err := dec.Decode(env.Program, readOnly, caseAddPublisher)
+	require.NoError(t, err)
+	assert.NotEqual(t, ins1, ins3)
+	assert.Equal(t, 2, dec.Len())
+
+	// Errors are cached as well.
+	_, err = dec.Decode(env.Program, accs, []byte{0x02})
+	assert.Error(t, err)
+	assert.Equal(t, 2, dec.Len(), "expected eviction")
+
+	// Least recently used entry was evicted.
+	before := dec.Len()
+	_, err = dec.Decode(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+	assert.Equal(t, before, dec.Len())
+}
+
+func TestCachedDecoder_MutatedInput(t *testing.T) {
+	var env = Devnet
+	var funding = solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	var price = solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	accs := []*solana.AccountMeta{
+		solana.Meta(funding).SIGNER().WRITE(),
+		solana.Meta(price).SIGNER().WRITE(),
+	}
+
+	dec := NewCachedDecoder(2)
+	ins1, err := dec.Decode(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+
+	// Caller reuses its buffer for another transaction.
+	accs[0].PublicKey = price
+	accs[1].PublicKey = funding
+	ins1.Accounts()[0].IsWritable = false
+
+	accs = []*solana.AccountMeta{
+		solana.Meta(funding).SIGNER().WRITE(),
+		solana.Meta(price).SIGNER().WRITE(),
+	}
+	ins2, err := dec.Decode(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dec.Len(), "expected cache hit")
+	assert.Equal(t, accs, ins2.Accounts())
+}
+
+func TestCachedDecoder_MutatedPayload(t *testing.T) {
+	var env = Devnet
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko")).SIGNER().WRITE(),
+	}
+	expected, err := DecodeInstruction(env.Program, accs, caseUpdProduct)
+	require.NoError(t, err)
+
+	dec := NewCachedDecoder(2)
+	ins1, err := dec.Decode(env.Program, accs, caseUpdProduct)
+	require.NoError(t, err)
+	ins1.Payload.(*CommandUpdProduct).Pairs[0][1] = "modified"
+	ins1.Header.Cmd = Instruction_AddPrice
+
+	ins2, err := dec.Decode(env.Program, accs, caseUpdProduct)
+	require.NoError(t, err)
+	assert.Equal(t, expected, ins2)
+	ins2.Payload.(*CommandUpdProduct).Pairs[0][1] = "modified"
+
+	ins3, err := dec.Decode(env.Program, accs, caseUpdProduct)
+	require.NoError(t, err)
+	assert.Equal(t, expected, ins3)
+
+	pub1, err := dec.Decode(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+	pub1.Payload.(*CommandAddPublisher).Publisher = solana.PublicKey{}
+	pub2, err := dec.Decode(env.Program, accs, caseAddPublisher)
+	require.NoError(t, err)
+	assert.NotEqual(t, solana.PublicKey{}, pub2.Payload.(*CommandAddPublisher).Publisher)
+}
@@ -15,6 +15,9 @@
 package pyth
 
 import (
+	"sync"
+	"time"
+
 	"github.com/gagliardetto/solana-go/rpc"
 	"go.uber.org/zap"
 )
@@ -28,7 +31,17 @@ type Client struct {
 	WebSocketURL string
 	Log          *zap.Logger
 
-	AccountsBatchSize int // number of accounts to get with getMultipleAccounts()
+	AccountsBatchSize int           // number of accounts to get with getMultipleAccounts()
+	SubscribeRate     int           // max number of accountSubscribe requests per second
+	ExponentCacheTTL  time.Duration // how long DecodeAndFormatUpdPrice caches exponents, zero disables caching
+
+	exponents sync.Map // price account key to *cachedExponent
+}
+
+// cachedExponent is the exponent of a price account cached by DecodeAndFormatUpdPrice.
+type cachedExponent struct {
+	exponent int32
+	expires  time.Time
 }
 
 // NewClient creates a new client to the Pyth on-chain program.
@@ -40,5 +53,7 @@ func NewClient(env Env, rpcURL string, wsURL string) *Client {
 		Log:          zap.NewNop(),
 
 		AccountsBatchSize: 32,
+		SubscribeRate:     50,
+		ExponentCacheTTL:  time.Minute,
 	}
 }
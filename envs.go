@@ -14,7 +14,12 @@
 
 package pyth
 
-import "github.com/gagliardetto/solana-go"
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+)
 
 // Env identifies deployment of the Pyth on-chain program.
 type Env struct {
@@ -39,3 +44,67 @@ var Mainnet = Env{
 	Program: solana.MustPublicKeyFromBase58("FsJ3A3u2vn5cTVofAjvy6y5kwABJAqYWpe4975bi2epH"),
 	Mapping: solana.MustPublicKeyFromBase58("AHtgzX45WTKfkPG53L6WYhGEXwQkN1BVknET3sVsLL8J"),
 }
+
+// EnvByName returns the Pyth deployment with the given network name.
+//
+// Supported names are "devnet", "testnet", and "mainnet".
+func EnvByName(name string) (Env, error) {
+	switch name {
+	case "devnet":
+		return Devnet, nil
+	case "testnet":
+		return Testnet, nil
+	case "mainnet":
+		return Mainnet, nil
+	default:
+		return Env{}, fmt.Errorf("unknown network %q", name)
+	}
+}
+
+// networkNames lists the network names of the canonical deployments as accepted by EnvByName.
+var networkNames = []string{"devnet", "testnet", "mainnet"}
+
+// NetworkByProgram returns the network name of the canonical deployment with the given program ID.
+//
+// Returns false for other programs, including custom deployments registered with RegisterProgramID.
+func NetworkByProgram(key solana.PublicKey) (string, bool) {
+	for _, name := range networkNames {
+		if env, _ := EnvByName(name); env.Program == key {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// customEnvs holds deployments registered via RegisterProgramID, keyed by program ID.
+var customEnvs = struct {
+	sync.RWMutex
+	m map[solana.PublicKey]Env
+}{m: make(map[solana.PublicKey]Env)}
+
+// RegisterProgramID registers a custom Pyth deployment, e.g. a fork or localnet program.
+//
+// The program is registered as a solana-go instruction decoder
+// and recognized by transaction-level decoders such as DecodeInnerInstructions.
+// The Program field of env is replaced by key.
+func RegisterProgramID(key solana.PublicKey, env Env) {
+	env.Program = key
+	customEnvs.Lock()
+	customEnvs.m[key] = env
+	customEnvs.Unlock()
+	solana.RegisterInstructionDecoder(key, newInstructionDecoder(key))
+}
+
+// EnvByProgram returns the Pyth deployment with the given program ID,
+// including custom deployments registered with RegisterProgramID.
+func EnvByProgram(key solana.PublicKey) (Env, bool) {
+	for _, env := range []Env{Devnet, Testnet, Mainnet} {
+		if env.Program == key {
+			return env, true
+		}
+	}
+	customEnvs.RLock()
+	defer customEnvs.RUnlock()
+	env, ok := customEnvs.m[key]
+	return env, ok
+}
@@ -0,0 +1,82 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvByName(t *testing.T) {
+	env, err := EnvByName("devnet")
+	require.NoError(t, err)
+	assert.Equal(t, Devnet, env)
+	env, err = EnvByName("testnet")
+	require.NoError(t, err)
+	assert.Equal(t, Testnet, env)
+	env, err = EnvByName("mainnet")
+	require.NoError(t, err)
+	assert.Equal(t, Mainnet, env)
+
+	_, err = EnvByName("localnet")
+	assert.EqualError(t, err, `unknown network "localnet"`)
+}
+
+func TestRegisterProgramID(t *testing.T) {
+	localnet := solana.NewWallet().PublicKey()
+	_, ok := EnvByProgram(localnet)
+	assert.False(t, ok)
+
+	RegisterProgramID(localnet, Env{Mapping: Devnet.Mapping})
+	env, ok := EnvByProgram(localnet)
+	require.True(t, ok)
+	assert.Equal(t, Env{Program: localnet, Mapping: Devnet.Mapping}, env)
+	assert.True(t, isKnownProgram(localnet))
+
+	env, ok = EnvByProgram(Mainnet.Program)
+	require.True(t, ok)
+	assert.Equal(t, Mainnet, env)
+
+	// Instructions of the custom program are decodable via solana-go.
+	accs := []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(Devnet.Mapping).SIGNER().WRITE(),
+	}
+	decoded, err := solana.DecodeInstruction(localnet, accs, caseInitMapping)
+	require.NoError(t, err)
+	inst, ok := decoded.(*Instruction)
+	require.True(t, ok)
+	assert.Equal(t, localnet, inst.ProgramID())
+}
+
+func TestNetworkByProgram(t *testing.T) {
+	for _, name := range []string{"devnet", "testnet", "mainnet"} {
+		env, err := EnvByName(name)
+		require.NoError(t, err)
+		network, ok := NetworkByProgram(env.Program)
+		assert.True(t, ok)
+		assert.Equal(t, name, network)
+	}
+
+	custom := solana.NewWallet().PublicKey()
+	RegisterProgramID(custom, Devnet)
+	_, ok := NetworkByProgram(custom)
+	assert.False(t, ok)
+	_, ok = NewInstructionBuilder(custom).InitMapping(custom, custom).Network()
+	assert.False(t, ok)
+}
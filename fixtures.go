@@ -0,0 +1,88 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// WriteInstructionFixtures writes the instruction data of each InstructionBuilder method
+// with canonical arguments to the given directory.
+//
+// Each file is named after the instruction (e.g. "upd_price.bin").
+// The output is deterministic and matches the test cases in tests/instruction.
+func WriteInstructionFixtures(dir string) error {
+	for _, inst := range canonicalInstructions() {
+		data, err := inst.Data()
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(dir, InstructionIDToName(inst.Header.Cmd)+".bin")
+		if err := os.WriteFile(name, data, 0644); err != nil {
+			return fmt.Errorf("failed to write fixture: %w", err)
+		}
+	}
+	return nil
+}
+
+// canonicalInstructions returns one instruction built with fixed arguments per instruction type.
+func canonicalInstructions() []*Instruction {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	updPrice := CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Price:   261253500000,
+		Conf:    120500000,
+		PubSlot: 118774432,
+	}
+	updTest := CommandUpdTest{Exponent: -5}
+	for i := range updTest.Price {
+		updTest.SlotDiff[i] = int8(-i)
+		updTest.Price[i] = int64(100000 + i)
+		updTest.Conf[i] = uint64(i)
+	}
+	return []*Instruction{
+		builder.InitMapping(key, key),
+		builder.AddMapping(key, key, key),
+		builder.AddProduct(key, key, key),
+		builder.UpdProduct(key, key, CommandUpdProduct{
+			AttrsMap{
+				Pairs: [][2]string{
+					{"symbol", "FX.EUR/USD"},
+					{"asset_type", "FX"},
+					{"quote_currency", "USD"},
+					{"description", "EUR/USD"},
+					{"generic_symbol", "EURUSD"},
+					{"base", "EUR"},
+					{"tenor", "Spot"},
+				},
+			},
+		}),
+		builder.AddPrice(key, key, key, CommandAddPrice{Exponent: 0x3713, PriceType: PriceTypeValue(PriceTypePrice)}),
+		builder.AddPublisher(key, key, CommandAddPublisher{Publisher: key}),
+		builder.DelPublisher(key, key, CommandDelPublisher{Publisher: key}),
+		builder.UpdPrice(key, key, updPrice),
+		builder.AggPrice(key, key),
+		builder.InitPrice(key, key, CommandInitPrice{Exponent: -5, PriceType: PriceTypeValue(PriceTypePrice)}),
+		builder.InitTest(key, key),
+		builder.UpdTest(key, key, updTest),
+		builder.SetMinPub(key, key, CommandSetMinPub{MinPub: 69}),
+		builder.UpdPriceNoFailOnError(key, key, updPrice),
+	}
+}
@@ -0,0 +1,47 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteInstructionFixtures(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, WriteInstructionFixtures(dir))
+
+	for _, inst := range canonicalInstructions() {
+		name := InstructionIDToName(inst.Header.Cmd) + ".bin"
+		t.Run(name, func(t *testing.T) {
+			generated, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+
+			// Generated fixtures must match the checked-in test cases byte by byte.
+			expected, err := os.ReadFile(filepath.Join("tests", "instruction", name))
+			require.NoError(t, err)
+			assert.Equal(t, expected, generated)
+
+			// Round-trip through the decoder.
+			decoded, err := DecodeInstruction(inst.ProgramID(), inst.Accounts(), generated)
+			require.NoError(t, err)
+			assert.Equal(t, inst, decoded)
+		})
+	}
+}
@@ -0,0 +1,303 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"fmt"
+	"math"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// GeyserTransactionUpdate is the subset of a Geyser transaction update required to decode Pyth instructions.
+//
+// Implement this interface to wrap the transaction type of the Geyser client in use.
+type GeyserTransactionUpdate interface {
+	// MessageBytes returns the serialized transaction message, either legacy or versioned (v0).
+	MessageBytes() []byte
+	// AccountKeys returns all account keys of the transaction in order:
+	// static keys of the message, followed by writable and read-only addresses loaded from lookup tables.
+	//
+	// May return nil for legacy transactions, in which case the static keys are used.
+	AccountKeys() []solana.PublicKey
+}
+
+// DecodeGeyserTransaction decodes all top-level Pyth instructions of a transaction streamed by a Geyser plugin.
+//
+// Versioned transactions using address lookup tables are supported,
+// given that the update provides the loaded addresses.
+// Instructions of programs other than the known Pyth deployments are skipped.
+func DecodeGeyserTransaction(update GeyserTransactionUpdate) ([]*Instruction, error) {
+	msg, err := parseRawMessage(update.MessageBytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	decoded, err := decodeRawMessage(msg, update.AccountKeys(), nil)
+	return decodedInstructions(decoded), err
+}
+
+// decodeRawInstruction decodes a compiled instruction given the account metas of its message.
+//
+// Returns nil if the instruction does not belong to a known Pyth program.
+func decodeRawInstruction(metas []*solana.AccountMeta, compiled *rawInstruction, opts ...DecodeOption) (*Instruction, error) {
+	if int(compiled.programIndex) >= len(metas) {
+		return nil, fmt.Errorf("program index %d out of range", compiled.programIndex)
+	}
+	programKey := metas[compiled.programIndex].PublicKey
+	if !isKnownProgram(programKey) {
+		return nil, nil
+	}
+	accounts := make([]*solana.AccountMeta, len(compiled.accounts))
+	for i, index := range compiled.accounts {
+		if int(index) >= len(metas) {
+			return nil, fmt.Errorf("account index %d out of range", index)
+		}
+		accounts[i] = metas[index]
+	}
+	return DecodeInstruction(programKey, accounts, compiled.data, opts...)
+}
+
+// DecodeInstructionFromIndices decodes an instruction that references accounts by index into the account keys of its message.
+//
+// The signer and writable flags of accounts are reconstructed from the counts of the message header.
+// All keys are treated as static keys of the message, i.e. without addresses loaded from lookup tables.
+func DecodeInstructionFromIndices(
+	programKey solana.PublicKey,
+	allKeys []solana.PublicKey,
+	signerCount, readonlySigned, readonlyUnsigned int,
+	accountIndices []uint16,
+	data []byte,
+) (*Instruction, error) {
+	if signerCount < 0 || signerCount > math.MaxUint8 || signerCount > len(allKeys) ||
+		readonlySigned < 0 || readonlySigned > signerCount ||
+		readonlyUnsigned < 0 || readonlyUnsigned > math.MaxUint8 || readonlyUnsigned > len(allKeys)-signerCount {
+		return nil, fmt.Errorf("invalid message header for %d account keys", len(allKeys))
+	}
+	msg := rawMessage{
+		header: solana.MessageHeader{
+			NumRequiredSignatures:       uint8(signerCount),
+			NumReadonlySignedAccounts:   uint8(readonlySigned),
+			NumReadonlyUnsignedAccounts: uint8(readonlyUnsigned),
+		},
+		staticKeys: allKeys,
+	}
+	metas, err := msg.accountMetas(allKeys)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]*solana.AccountMeta, len(accountIndices))
+	for i, index := range accountIndices {
+		if int(index) >= len(metas) {
+			return nil, fmt.Errorf("account index %d out of range", index)
+		}
+		accounts[i] = metas[index]
+	}
+	return DecodeInstruction(programKey, accounts, data)
+}
+
+// rawMessage is a transaction message decoded without resolving address lookup tables.
+type rawMessage struct {
+	header            solana.MessageHeader
+	staticKeys        []solana.PublicKey
+	instructions      []rawInstruction
+	lookups           []rawLookup
+	numLoadedWritable int
+	numLoadedReadonly int
+}
+
+// rawLookup references addresses of an address lookup table by index.
+type rawLookup struct {
+	table    solana.PublicKey
+	writable []uint8
+	readonly []uint8
+}
+
+// rawInstruction is a compiled instruction referencing accounts by index.
+type rawInstruction struct {
+	programIndex uint8
+	accounts     []uint8
+	data         []byte
+}
+
+// parseRawMessage decodes a legacy or v0 transaction message.
+func parseRawMessage(data []byte) (*rawMessage, error) {
+	dec := bin.NewBinDecoder(data)
+	versioned := len(data) > 0 && data[0]&0x80 != 0
+	if versioned {
+		if version := data[0] &^ 0x80; version != 0 {
+			return nil, fmt.Errorf("unsupported message version %d", version)
+		}
+		_ = dec.SkipBytes(1)
+	}
+
+	msg := new(rawMessage)
+	header, err := dec.ReadNBytes(3)
+	if err != nil {
+		return nil, err
+	}
+	msg.header = solana.MessageHeader{
+		NumRequiredSignatures:       header[0],
+		NumReadonlySignedAccounts:   header[1],
+		NumReadonlyUnsignedAccounts: header[2],
+	}
+	numKeys, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return nil, err
+	}
+	msg.staticKeys = make([]solana.PublicKey, numKeys)
+	for i := range msg.staticKeys {
+		key, err := dec.ReadNBytes(solana.PublicKeyLength)
+		if err != nil {
+			return nil, err
+		}
+		msg.staticKeys[i] = solana.PublicKeyFromBytes(key)
+	}
+	if err := dec.SkipBytes(32); err != nil { // recent blockhash
+		return nil, err
+	}
+	numInsts, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return nil, err
+	}
+	msg.instructions = make([]rawInstruction, numInsts)
+	for i := range msg.instructions {
+		inst := &msg.instructions[i]
+		if inst.programIndex, err = dec.ReadByte(); err != nil {
+			return nil, err
+		}
+		if inst.accounts, err = readCompactBytes(dec); err != nil {
+			return nil, err
+		}
+		if inst.data, err = readCompactBytes(dec); err != nil {
+			return nil, err
+		}
+	}
+	if !versioned {
+		return msg, nil
+	}
+
+	numLookups, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return nil, err
+	}
+	msg.lookups = make([]rawLookup, numLookups)
+	for i := range msg.lookups {
+		lookup := &msg.lookups[i]
+		table, err := dec.ReadNBytes(solana.PublicKeyLength)
+		if err != nil {
+			return nil, err
+		}
+		lookup.table = solana.PublicKeyFromBytes(table)
+		if lookup.writable, err = readCompactBytes(dec); err != nil {
+			return nil, err
+		}
+		if lookup.readonly, err = readCompactBytes(dec); err != nil {
+			return nil, err
+		}
+		msg.numLoadedWritable += len(lookup.writable)
+		msg.numLoadedReadonly += len(lookup.readonly)
+	}
+	return msg, nil
+}
+
+// resolveKeys returns the static keys of the message followed by the writable and read-only
+// addresses loaded from lookup tables, as expected by accountMetas.
+//
+// resolve may be nil if the message does not use lookup tables.
+func (m *rawMessage) resolveKeys(resolve AddressLookupTableResolver) ([]solana.PublicKey, error) {
+	keys := append([]solana.PublicKey{}, m.staticKeys...)
+	if len(m.lookups) == 0 {
+		return keys, nil
+	}
+	if resolve == nil {
+		return nil, fmt.Errorf("message loads accounts from %d lookup tables", len(m.lookups))
+	}
+	tables := make([][]solana.PublicKey, len(m.lookups))
+	for i, lookup := range m.lookups {
+		addrs, err := resolve(lookup.table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve lookup table %s: %w", lookup.table, err)
+		}
+		tables[i] = addrs
+	}
+	var err error
+	for i, lookup := range m.lookups {
+		if keys, err = appendLookupKeys(keys, lookup.table, tables[i], lookup.writable); err != nil {
+			return nil, err
+		}
+	}
+	for i, lookup := range m.lookups {
+		if keys, err = appendLookupKeys(keys, lookup.table, tables[i], lookup.readonly); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// appendLookupKeys appends the addresses at the given indexes of a lookup table.
+func appendLookupKeys(keys []solana.PublicKey, table solana.PublicKey, addrs []solana.PublicKey, indexes []uint8) ([]solana.PublicKey, error) {
+	for _, index := range indexes {
+		if int(index) >= len(addrs) {
+			return nil, fmt.Errorf("index %d out of range of lookup table %s", index, table)
+		}
+		keys = append(keys, addrs[index])
+	}
+	return keys, nil
+}
+
+// readCompactBytes reads a byte slice prefixed with a compact-u16 length.
+func readCompactBytes(dec *bin.Decoder) ([]byte, error) {
+	n, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return nil, err
+	}
+	return dec.ReadNBytes(n)
+}
+
+// accountMetas returns the account metas of all accounts referenced by the message.
+//
+// Keys must contain the static keys followed by all loaded addresses.
+// If keys is empty, only the static keys of the message are used.
+func (m *rawMessage) accountMetas(keys []solana.PublicKey) ([]*solana.AccountMeta, error) {
+	numLoaded := m.numLoadedWritable + m.numLoadedReadonly
+	if len(keys) == 0 && numLoaded == 0 {
+		keys = m.staticKeys
+	}
+	if len(keys) != len(m.staticKeys)+numLoaded {
+		return nil, fmt.Errorf("expected %d account keys but got %d", len(m.staticKeys)+numLoaded, len(keys))
+	}
+	numSigners := int(m.header.NumRequiredSignatures)
+	numWritableSigners := numSigners - int(m.header.NumReadonlySignedAccounts)
+	numWritableUnsigned := len(m.staticKeys) - int(m.header.NumReadonlyUnsignedAccounts)
+	metas := make([]*solana.AccountMeta, len(keys))
+	for i, key := range keys {
+		var writable, signer bool
+		switch {
+		case i < numSigners:
+			signer = true
+			writable = i < numWritableSigners
+		case i < len(m.staticKeys):
+			writable = i < numWritableUnsigned
+		default:
+			writable = i-len(m.staticKeys) < m.numLoadedWritable
+		}
+		if i < len(m.staticKeys) && key != m.staticKeys[i] {
+			return nil, fmt.Errorf("account key #%d does not match message", i)
+		}
+		metas[i] = solana.NewAccountMeta(key, writable, signer)
+	}
+	return metas, nil
+}
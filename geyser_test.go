@@ -0,0 +1,120 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testGeyserUpdate struct {
+	message []byte
+	keys    []solana.PublicKey
+}
+
+func (u *testGeyserUpdate) MessageBytes() []byte            { return u.message }
+func (u *testGeyserUpdate) AccountKeys() []solana.PublicKey { return u.keys }
+
+func TestDecodeGeyserTransaction_Legacy(t *testing.T) {
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	updPrice := NewInstructionBuilder(Devnet.Program).UpdPrice(publisher, priceKey, CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Price:   261253500000,
+		Conf:    120500000,
+		PubSlot: 118774432,
+	})
+	other := solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("hello"))
+	tx, err := solana.NewTransaction([]solana.Instruction{other, updPrice}, solana.Hash{}, solana.TransactionPayer(publisher))
+	require.NoError(t, err)
+	msgData, err := tx.Message.MarshalBinary()
+	require.NoError(t, err)
+
+	insts, err := DecodeGeyserTransaction(&testGeyserUpdate{message: msgData})
+	require.NoError(t, err)
+	assert.Equal(t, []*Instruction{updPrice}, insts)
+
+	insts, err = DecodeGeyserTransaction(&testGeyserUpdate{message: msgData, keys: tx.Message.AccountKeys})
+	require.NoError(t, err)
+	assert.Equal(t, []*Instruction{updPrice}, insts)
+
+	_, err = DecodeGeyserTransaction(&testGeyserUpdate{message: msgData, keys: tx.Message.AccountKeys[:1]})
+	assert.EqualError(t, err, "expected 5 account keys but got 1")
+}
+
+func TestDecodeGeyserTransaction_V0(t *testing.T) {
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	table := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	// Static keys: publisher, program. Loaded: price (writable), clock (read-only).
+	msg := []byte{0x80, 1, 0, 1, 2}
+	msg = append(msg, publisher[:]...)
+	msg = append(msg, Devnet.Program[:]...)
+	msg = append(msg, make([]byte, 32)...) // blockhash
+	msg = append(msg, 1)                   // one instruction
+	msg = append(msg, 1, 3, 0, 2, 3)       // program index, account indexes
+	msg = append(msg, byte(len(caseUpdPrice)))
+	msg = append(msg, caseUpdPrice...)
+	msg = append(msg, 1) // one lookup table
+	msg = append(msg, table[:]...)
+	msg = append(msg, 1, 7) // writable indexes
+	msg = append(msg, 1, 9) // read-only indexes
+
+	update := &testGeyserUpdate{
+		message: msg,
+		keys:    []solana.PublicKey{publisher, Devnet.Program, priceKey, solana.SysVarClockPubkey},
+	}
+	insts, err := DecodeGeyserTransaction(update)
+	require.NoError(t, err)
+	require.Len(t, insts, 1)
+	assert.Equal(t, []*solana.AccountMeta{
+		solana.Meta(publisher).SIGNER().WRITE(),
+		solana.Meta(priceKey).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}, insts[0].Accounts())
+	assert.Equal(t, Instruction_UpdPrice, insts[0].Header.Cmd)
+
+	update.keys = nil
+	_, err = DecodeGeyserTransaction(update)
+	assert.EqualError(t, err, "expected 4 account keys but got 0")
+
+	msg[0] = 0x81
+	_, err = DecodeGeyserTransaction(update)
+	assert.EqualError(t, err, "failed to decode message: unsupported message version 1")
+}
+
+func TestDecodeInstructionFromIndices(t *testing.T) {
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	keys := []solana.PublicKey{publisher, priceKey, solana.SysVarClockPubkey, Devnet.Program}
+
+	inst, err := DecodeInstructionFromIndices(Devnet.Program, keys, 1, 0, 2, []uint16{0, 1, 2}, caseUpdPrice)
+	require.NoError(t, err)
+	assert.Equal(t, []*solana.AccountMeta{
+		solana.Meta(publisher).SIGNER().WRITE(),
+		solana.Meta(priceKey).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}, inst.Accounts())
+	assert.Equal(t, Instruction_UpdPrice, inst.Header.Cmd)
+
+	_, err = DecodeInstructionFromIndices(Devnet.Program, keys, 1, 0, 2, []uint16{0, 1, 4}, caseUpdPrice)
+	assert.EqualError(t, err, "account index 4 out of range")
+	_, err = DecodeInstructionFromIndices(Devnet.Program, keys, 1, 2, 2, []uint16{0, 1, 2}, caseUpdPrice)
+	assert.EqualError(t, err, "invalid message header for 4 account keys")
+}
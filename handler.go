@@ -173,7 +173,7 @@ type PriceUpdate struct {
 // If ok is false, the value is invalid.
 func (p PriceUpdate) Previous() (price decimal.Decimal, conf decimal.Decimal, ok bool) {
 	if !p.PreviousInfo.IsZero() && p.Account != nil {
-		p.PreviousInfo.Value(p.Account.Exponent)
+		return p.PreviousInfo.Value(p.Account.Exponent())
 	}
 	return
 }
@@ -183,7 +183,7 @@ func (p PriceUpdate) Previous() (price decimal.Decimal, conf decimal.Decimal, ok
 // If ok is false, the value is invalid.
 func (p PriceUpdate) Current() (price decimal.Decimal, conf decimal.Decimal, ok bool) {
 	if !p.CurrentInfo.IsZero() && p.Account != nil {
-		return p.CurrentInfo.Value(p.Account.Exponent)
+		return p.CurrentInfo.Value(p.Account.Exponent())
 	}
 	return
 }
@@ -16,9 +16,11 @@ package pyth
 
 import (
 	"log"
+	"testing"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
 )
 
 func ExamplePriceEventHandler() {
@@ -42,3 +44,25 @@ func ExamplePriceEventHandler() {
 	<-time.After(10 * time.Second)
 	stream.Close()
 }
+
+func TestPriceUpdate(t *testing.T) {
+	update := PriceUpdate{
+		Account:      &PriceAccount{Expo: -5},
+		PreviousInfo: &PriceInfo{Price: 200000, Conf: 50, Status: PriceStatusTrading},
+		CurrentInfo:  &PriceInfo{Price: 210000, Conf: 60, Status: PriceStatusTrading},
+	}
+
+	price, conf, ok := update.Previous()
+	assert.True(t, ok)
+	assert.Equal(t, "2", price.String())
+	assert.Equal(t, "0.0005", conf.String())
+
+	price, conf, ok = update.Current()
+	assert.True(t, ok)
+	assert.Equal(t, "2.1", price.String())
+	assert.Equal(t, "0.0006", conf.String())
+
+	update.PreviousInfo = nil
+	_, _, ok = update.Previous()
+	assert.False(t, ok)
+}
@@ -0,0 +1,1145 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Protobuf representation of decoded Pyth instructions.
+//
+// Go code in instruction.pb.go is generated with protoc-gen-go, see protobuf.go.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        (unknown)
+// source: instruction.proto
+
+package pyth
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CommandPB identifies the instruction type, matching the Pyth command IDs.
+type CommandPB int32
+
+const (
+	CommandPB_INIT_MAPPING               CommandPB = 0
+	CommandPB_ADD_MAPPING                CommandPB = 1
+	CommandPB_ADD_PRODUCT                CommandPB = 2
+	CommandPB_UPD_PRODUCT                CommandPB = 3
+	CommandPB_ADD_PRICE                  CommandPB = 4
+	CommandPB_ADD_PUBLISHER              CommandPB = 5
+	CommandPB_DEL_PUBLISHER              CommandPB = 6
+	CommandPB_UPD_PRICE                  CommandPB = 7
+	CommandPB_AGG_PRICE                  CommandPB = 8
+	CommandPB_INIT_PRICE                 CommandPB = 9
+	CommandPB_INIT_TEST                  CommandPB = 10
+	CommandPB_UPD_TEST                   CommandPB = 11
+	CommandPB_SET_MIN_PUB                CommandPB = 12
+	CommandPB_UPD_PRICE_NO_FAIL_ON_ERROR CommandPB = 13
+)
+
+// Enum value maps for CommandPB.
+var (
+	CommandPB_name = map[int32]string{
+		0:  "INIT_MAPPING",
+		1:  "ADD_MAPPING",
+		2:  "ADD_PRODUCT",
+		3:  "UPD_PRODUCT",
+		4:  "ADD_PRICE",
+		5:  "ADD_PUBLISHER",
+		6:  "DEL_PUBLISHER",
+		7:  "UPD_PRICE",
+		8:  "AGG_PRICE",
+		9:  "INIT_PRICE",
+		10: "INIT_TEST",
+		11: "UPD_TEST",
+		12: "SET_MIN_PUB",
+		13: "UPD_PRICE_NO_FAIL_ON_ERROR",
+	}
+	CommandPB_value = map[string]int32{
+		"INIT_MAPPING":               0,
+		"ADD_MAPPING":                1,
+		"ADD_PRODUCT":                2,
+		"UPD_PRODUCT":                3,
+		"ADD_PRICE":                  4,
+		"ADD_PUBLISHER":              5,
+		"DEL_PUBLISHER":              6,
+		"UPD_PRICE":                  7,
+		"AGG_PRICE":                  8,
+		"INIT_PRICE":                 9,
+		"INIT_TEST":                  10,
+		"UPD_TEST":                   11,
+		"SET_MIN_PUB":                12,
+		"UPD_PRICE_NO_FAIL_ON_ERROR": 13,
+	}
+)
+
+func (x CommandPB) Enum() *CommandPB {
+	p := new(CommandPB)
+	*p = x
+	return p
+}
+
+func (x CommandPB) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CommandPB) Descriptor() protoreflect.EnumDescriptor {
+	return file_instruction_proto_enumTypes[0].Descriptor()
+}
+
+func (CommandPB) Type() protoreflect.EnumType {
+	return &file_instruction_proto_enumTypes[0]
+}
+
+func (x CommandPB) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CommandPB.Descriptor instead.
+func (CommandPB) EnumDescriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{0}
+}
+
+// PriceStatusPB is the status of a price update.
+type PriceStatusPB int32
+
+const (
+	PriceStatusPB_UNKNOWN PriceStatusPB = 0
+	PriceStatusPB_TRADING PriceStatusPB = 1
+	PriceStatusPB_HALTED  PriceStatusPB = 2
+	PriceStatusPB_AUCTION PriceStatusPB = 3
+)
+
+// Enum value maps for PriceStatusPB.
+var (
+	PriceStatusPB_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "TRADING",
+		2: "HALTED",
+		3: "AUCTION",
+	}
+	PriceStatusPB_value = map[string]int32{
+		"UNKNOWN": 0,
+		"TRADING": 1,
+		"HALTED":  2,
+		"AUCTION": 3,
+	}
+)
+
+func (x PriceStatusPB) Enum() *PriceStatusPB {
+	p := new(PriceStatusPB)
+	*p = x
+	return p
+}
+
+func (x PriceStatusPB) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PriceStatusPB) Descriptor() protoreflect.EnumDescriptor {
+	return file_instruction_proto_enumTypes[1].Descriptor()
+}
+
+func (PriceStatusPB) Type() protoreflect.EnumType {
+	return &file_instruction_proto_enumTypes[1]
+}
+
+func (x PriceStatusPB) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PriceStatusPB.Descriptor instead.
+func (PriceStatusPB) EnumDescriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{1}
+}
+
+// InstructionPB is the protobuf representation of an Instruction.
+type InstructionPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Program  []byte           `protobuf:"bytes,1,opt,name=program,proto3" json:"program,omitempty"`  // program ID (32 bytes)
+	Version  uint32           `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // command header version
+	Command  CommandPB        `protobuf:"varint,3,opt,name=command,proto3,enum=pyth.CommandPB" json:"command,omitempty"`
+	Accounts []*AccountMetaPB `protobuf:"bytes,4,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	// Types that are assignable to Payload:
+	//	*InstructionPB_UpdProduct
+	//	*InstructionPB_AddPrice
+	//	*InstructionPB_AddPublisher
+	//	*InstructionPB_DelPublisher
+	//	*InstructionPB_UpdPrice
+	//	*InstructionPB_InitPrice
+	//	*InstructionPB_UpdTest
+	//	*InstructionPB_SetMinPub
+	Payload isInstructionPB_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *InstructionPB) Reset() {
+	*x = InstructionPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InstructionPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstructionPB) ProtoMessage() {}
+
+func (x *InstructionPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstructionPB.ProtoReflect.Descriptor instead.
+func (*InstructionPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *InstructionPB) GetProgram() []byte {
+	if x != nil {
+		return x.Program
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *InstructionPB) GetCommand() CommandPB {
+	if x != nil {
+		return x.Command
+	}
+	return CommandPB_INIT_MAPPING
+}
+
+func (x *InstructionPB) GetAccounts() []*AccountMetaPB {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (m *InstructionPB) GetPayload() isInstructionPB_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetUpdProduct() *UpdProductPB {
+	if x, ok := x.GetPayload().(*InstructionPB_UpdProduct); ok {
+		return x.UpdProduct
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetAddPrice() *PriceConfigPB {
+	if x, ok := x.GetPayload().(*InstructionPB_AddPrice); ok {
+		return x.AddPrice
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetAddPublisher() *PublisherPB {
+	if x, ok := x.GetPayload().(*InstructionPB_AddPublisher); ok {
+		return x.AddPublisher
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetDelPublisher() *PublisherPB {
+	if x, ok := x.GetPayload().(*InstructionPB_DelPublisher); ok {
+		return x.DelPublisher
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetUpdPrice() *UpdPricePB {
+	if x, ok := x.GetPayload().(*InstructionPB_UpdPrice); ok {
+		return x.UpdPrice
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetInitPrice() *PriceConfigPB {
+	if x, ok := x.GetPayload().(*InstructionPB_InitPrice); ok {
+		return x.InitPrice
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetUpdTest() *UpdTestPB {
+	if x, ok := x.GetPayload().(*InstructionPB_UpdTest); ok {
+		return x.UpdTest
+	}
+	return nil
+}
+
+func (x *InstructionPB) GetSetMinPub() *SetMinPubPB {
+	if x, ok := x.GetPayload().(*InstructionPB_SetMinPub); ok {
+		return x.SetMinPub
+	}
+	return nil
+}
+
+type isInstructionPB_Payload interface {
+	isInstructionPB_Payload()
+}
+
+type InstructionPB_UpdProduct struct {
+	UpdProduct *UpdProductPB `protobuf:"bytes,10,opt,name=upd_product,json=updProduct,proto3,oneof"`
+}
+
+type InstructionPB_AddPrice struct {
+	AddPrice *PriceConfigPB `protobuf:"bytes,11,opt,name=add_price,json=addPrice,proto3,oneof"`
+}
+
+type InstructionPB_AddPublisher struct {
+	AddPublisher *PublisherPB `protobuf:"bytes,12,opt,name=add_publisher,json=addPublisher,proto3,oneof"`
+}
+
+type InstructionPB_DelPublisher struct {
+	DelPublisher *PublisherPB `protobuf:"bytes,13,opt,name=del_publisher,json=delPublisher,proto3,oneof"`
+}
+
+type InstructionPB_UpdPrice struct {
+	UpdPrice *UpdPricePB `protobuf:"bytes,14,opt,name=upd_price,json=updPrice,proto3,oneof"` // also used by UPD_PRICE_NO_FAIL_ON_ERROR
+}
+
+type InstructionPB_InitPrice struct {
+	InitPrice *PriceConfigPB `protobuf:"bytes,15,opt,name=init_price,json=initPrice,proto3,oneof"`
+}
+
+type InstructionPB_UpdTest struct {
+	UpdTest *UpdTestPB `protobuf:"bytes,16,opt,name=upd_test,json=updTest,proto3,oneof"`
+}
+
+type InstructionPB_SetMinPub struct {
+	SetMinPub *SetMinPubPB `protobuf:"bytes,17,opt,name=set_min_pub,json=setMinPub,proto3,oneof"`
+}
+
+func (*InstructionPB_UpdProduct) isInstructionPB_Payload() {}
+
+func (*InstructionPB_AddPrice) isInstructionPB_Payload() {}
+
+func (*InstructionPB_AddPublisher) isInstructionPB_Payload() {}
+
+func (*InstructionPB_DelPublisher) isInstructionPB_Payload() {}
+
+func (*InstructionPB_UpdPrice) isInstructionPB_Payload() {}
+
+func (*InstructionPB_InitPrice) isInstructionPB_Payload() {}
+
+func (*InstructionPB_UpdTest) isInstructionPB_Payload() {}
+
+func (*InstructionPB_SetMinPub) isInstructionPB_Payload() {}
+
+type AccountMetaPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pubkey   []byte `protobuf:"bytes,1,opt,name=pubkey,proto3" json:"pubkey,omitempty"` // account key (32 bytes)
+	Signer   bool   `protobuf:"varint,2,opt,name=signer,proto3" json:"signer,omitempty"`
+	Writable bool   `protobuf:"varint,3,opt,name=writable,proto3" json:"writable,omitempty"`
+}
+
+func (x *AccountMetaPB) Reset() {
+	*x = AccountMetaPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccountMetaPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountMetaPB) ProtoMessage() {}
+
+func (x *AccountMetaPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountMetaPB.ProtoReflect.Descriptor instead.
+func (*AccountMetaPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AccountMetaPB) GetPubkey() []byte {
+	if x != nil {
+		return x.Pubkey
+	}
+	return nil
+}
+
+func (x *AccountMetaPB) GetSigner() bool {
+	if x != nil {
+		return x.Signer
+	}
+	return false
+}
+
+func (x *AccountMetaPB) GetWritable() bool {
+	if x != nil {
+		return x.Writable
+	}
+	return false
+}
+
+type UpdProductPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Attrs []*AttrPB `protobuf:"bytes,1,rep,name=attrs,proto3" json:"attrs,omitempty"` // in encoding order
+}
+
+func (x *UpdProductPB) Reset() {
+	*x = UpdProductPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdProductPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdProductPB) ProtoMessage() {}
+
+func (x *UpdProductPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdProductPB.ProtoReflect.Descriptor instead.
+func (*UpdProductPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UpdProductPB) GetAttrs() []*AttrPB {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+type AttrPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *AttrPB) Reset() {
+	*x = AttrPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AttrPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttrPB) ProtoMessage() {}
+
+func (x *AttrPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttrPB.ProtoReflect.Descriptor instead.
+func (*AttrPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AttrPB) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *AttrPB) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type PriceConfigPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Exponent  int32  `protobuf:"zigzag32,1,opt,name=exponent,proto3" json:"exponent,omitempty"`
+	PriceType uint32 `protobuf:"varint,2,opt,name=price_type,json=priceType,proto3" json:"price_type,omitempty"`
+}
+
+func (x *PriceConfigPB) Reset() {
+	*x = PriceConfigPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PriceConfigPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceConfigPB) ProtoMessage() {}
+
+func (x *PriceConfigPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceConfigPB.ProtoReflect.Descriptor instead.
+func (*PriceConfigPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PriceConfigPB) GetExponent() int32 {
+	if x != nil {
+		return x.Exponent
+	}
+	return 0
+}
+
+func (x *PriceConfigPB) GetPriceType() uint32 {
+	if x != nil {
+		return x.PriceType
+	}
+	return 0
+}
+
+type PublisherPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Publisher []byte `protobuf:"bytes,1,opt,name=publisher,proto3" json:"publisher,omitempty"` // publisher key (32 bytes)
+}
+
+func (x *PublisherPB) Reset() {
+	*x = PublisherPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PublisherPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublisherPB) ProtoMessage() {}
+
+func (x *PublisherPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublisherPB.ProtoReflect.Descriptor instead.
+func (*PublisherPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PublisherPB) GetPublisher() []byte {
+	if x != nil {
+		return x.Publisher
+	}
+	return nil
+}
+
+type UpdPricePB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status  PriceStatusPB `protobuf:"varint,1,opt,name=status,proto3,enum=pyth.PriceStatusPB" json:"status,omitempty"`
+	Price   int64         `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
+	Conf    uint64        `protobuf:"varint,3,opt,name=conf,proto3" json:"conf,omitempty"`
+	PubSlot uint64        `protobuf:"varint,4,opt,name=pub_slot,json=pubSlot,proto3" json:"pub_slot,omitempty"`
+	Unused  uint32        `protobuf:"varint,5,opt,name=unused,proto3" json:"unused,omitempty"`
+}
+
+func (x *UpdPricePB) Reset() {
+	*x = UpdPricePB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdPricePB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdPricePB) ProtoMessage() {}
+
+func (x *UpdPricePB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdPricePB.ProtoReflect.Descriptor instead.
+func (*UpdPricePB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdPricePB) GetStatus() PriceStatusPB {
+	if x != nil {
+		return x.Status
+	}
+	return PriceStatusPB_UNKNOWN
+}
+
+func (x *UpdPricePB) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *UpdPricePB) GetConf() uint64 {
+	if x != nil {
+		return x.Conf
+	}
+	return 0
+}
+
+func (x *UpdPricePB) GetPubSlot() uint64 {
+	if x != nil {
+		return x.PubSlot
+	}
+	return 0
+}
+
+func (x *UpdPricePB) GetUnused() uint32 {
+	if x != nil {
+		return x.Unused
+	}
+	return 0
+}
+
+type UpdTestPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Exponent int32    `protobuf:"zigzag32,1,opt,name=exponent,proto3" json:"exponent,omitempty"`
+	SlotDiff []int32  `protobuf:"zigzag32,2,rep,packed,name=slot_diff,json=slotDiff,proto3" json:"slot_diff,omitempty"` // 32 entries
+	Price    []int64  `protobuf:"varint,3,rep,packed,name=price,proto3" json:"price,omitempty"`                         // 32 entries
+	Conf     []uint64 `protobuf:"varint,4,rep,packed,name=conf,proto3" json:"conf,omitempty"`                           // 32 entries
+}
+
+func (x *UpdTestPB) Reset() {
+	*x = UpdTestPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdTestPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdTestPB) ProtoMessage() {}
+
+func (x *UpdTestPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdTestPB.ProtoReflect.Descriptor instead.
+func (*UpdTestPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdTestPB) GetExponent() int32 {
+	if x != nil {
+		return x.Exponent
+	}
+	return 0
+}
+
+func (x *UpdTestPB) GetSlotDiff() []int32 {
+	if x != nil {
+		return x.SlotDiff
+	}
+	return nil
+}
+
+func (x *UpdTestPB) GetPrice() []int64 {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *UpdTestPB) GetConf() []uint64 {
+	if x != nil {
+		return x.Conf
+	}
+	return nil
+}
+
+type SetMinPubPB struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MinPub  uint32 `protobuf:"varint,1,opt,name=min_pub,json=minPub,proto3" json:"min_pub,omitempty"`
+	Padding []byte `protobuf:"bytes,2,opt,name=padding,proto3" json:"padding,omitempty"` // 3 bytes, usually zero
+}
+
+func (x *SetMinPubPB) Reset() {
+	*x = SetMinPubPB{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_instruction_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetMinPubPB) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMinPubPB) ProtoMessage() {}
+
+func (x *SetMinPubPB) ProtoReflect() protoreflect.Message {
+	mi := &file_instruction_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMinPubPB.ProtoReflect.Descriptor instead.
+func (*SetMinPubPB) Descriptor() ([]byte, []int) {
+	return file_instruction_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetMinPubPB) GetMinPub() uint32 {
+	if x != nil {
+		return x.MinPub
+	}
+	return 0
+}
+
+func (x *SetMinPubPB) GetPadding() []byte {
+	if x != nil {
+		return x.Padding
+	}
+	return nil
+}
+
+var File_instruction_proto protoreflect.FileDescriptor
+
+var file_instruction_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x69, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x04, 0x70, 0x79, 0x74, 0x68, 0x22, 0xd3, 0x04, 0x0a, 0x0d, 0x49, 0x6e,
+	0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x42, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x72, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x72,
+	0x6f, 0x67, 0x72, 0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x29, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0f, 0x2e, 0x70, 0x79, 0x74, 0x68, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x50,
+	0x42, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x2f, 0x0a, 0x08, 0x61, 0x63,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70,
+	0x79, 0x74, 0x68, 0x2e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x50,
+	0x42, 0x52, 0x08, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12, 0x35, 0x0a, 0x0b, 0x75,
+	0x70, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x70, 0x79, 0x74, 0x68, 0x2e, 0x55, 0x70, 0x64, 0x50, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x50, 0x42, 0x48, 0x00, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x50, 0x72, 0x6f, 0x64, 0x75,
+	0x63, 0x74, 0x12, 0x32, 0x0a, 0x09, 0x61, 0x64, 0x64, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x79, 0x74, 0x68, 0x2e, 0x50, 0x72, 0x69,
+	0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x42, 0x48, 0x00, 0x52, 0x08, 0x61, 0x64,
+	0x64, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x38, 0x0a, 0x0d, 0x61, 0x64, 0x64, 0x5f, 0x70, 0x75,
+	0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x70, 0x79, 0x74, 0x68, 0x2e, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x50, 0x42,
+	0x48, 0x00, 0x52, 0x0c, 0x61, 0x64, 0x64, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72,
+	0x12, 0x38, 0x0a, 0x0d, 0x64, 0x65, 0x6c, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65,
+	0x72, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x79, 0x74, 0x68, 0x2e, 0x50,
+	0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x50, 0x42, 0x48, 0x00, 0x52, 0x0c, 0x64, 0x65,
+	0x6c, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x12, 0x2f, 0x0a, 0x09, 0x75, 0x70,
+	0x64, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x70, 0x79, 0x74, 0x68, 0x2e, 0x55, 0x70, 0x64, 0x50, 0x72, 0x69, 0x63, 0x65, 0x50, 0x42, 0x48,
+	0x00, 0x52, 0x08, 0x75, 0x70, 0x64, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x0a, 0x69,
+	0x6e, 0x69, 0x74, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x13, 0x2e, 0x70, 0x79, 0x74, 0x68, 0x2e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x50, 0x42, 0x48, 0x00, 0x52, 0x09, 0x69, 0x6e, 0x69, 0x74, 0x50, 0x72, 0x69, 0x63,
+	0x65, 0x12, 0x2c, 0x0a, 0x08, 0x75, 0x70, 0x64, 0x5f, 0x74, 0x65, 0x73, 0x74, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x79, 0x74, 0x68, 0x2e, 0x55, 0x70, 0x64, 0x54, 0x65,
+	0x73, 0x74, 0x50, 0x42, 0x48, 0x00, 0x52, 0x07, 0x75, 0x70, 0x64, 0x54, 0x65, 0x73, 0x74, 0x12,
+	0x33, 0x0a, 0x0b, 0x73, 0x65, 0x74, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x75, 0x62, 0x18, 0x11,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x79, 0x74, 0x68, 0x2e, 0x53, 0x65, 0x74, 0x4d,
+	0x69, 0x6e, 0x50, 0x75, 0x62, 0x50, 0x42, 0x48, 0x00, 0x52, 0x09, 0x73, 0x65, 0x74, 0x4d, 0x69,
+	0x6e, 0x50, 0x75, 0x62, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22,
+	0x5b, 0x0a, 0x0d, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x50, 0x42,
+	0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x06, 0x70, 0x75, 0x62, 0x6b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72,
+	0x12, 0x1a, 0x0a, 0x08, 0x77, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x77, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x32, 0x0a, 0x0c,
+	0x55, 0x70, 0x64, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x74, 0x50, 0x42, 0x12, 0x22, 0x0a, 0x05,
+	0x61, 0x74, 0x74, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x79,
+	0x74, 0x68, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x50, 0x42, 0x52, 0x05, 0x61, 0x74, 0x74, 0x72, 0x73,
+	0x22, 0x30, 0x0a, 0x06, 0x41, 0x74, 0x74, 0x72, 0x50, 0x42, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x22, 0x4a, 0x0a, 0x0d, 0x50, 0x72, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x50, 0x42, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x11, 0x52, 0x08, 0x65, 0x78, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x09, 0x70, 0x72, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x22, 0x2b,
+	0x0a, 0x0b, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x50, 0x42, 0x12, 0x1c, 0x0a,
+	0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x72, 0x22, 0x96, 0x01, 0x0a, 0x0a,
+	0x55, 0x70, 0x64, 0x50, 0x72, 0x69, 0x63, 0x65, 0x50, 0x42, 0x12, 0x2b, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x70, 0x79, 0x74,
+	0x68, 0x2e, 0x50, 0x72, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x50, 0x42, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x63, 0x6f, 0x6e, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x63, 0x6f, 0x6e,
+	0x66, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x75, 0x62, 0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x70, 0x75, 0x62, 0x53, 0x6c, 0x6f, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x75, 0x6e, 0x75, 0x73, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x75, 0x6e,
+	0x75, 0x73, 0x65, 0x64, 0x22, 0x6e, 0x0a, 0x09, 0x55, 0x70, 0x64, 0x54, 0x65, 0x73, 0x74, 0x50,
+	0x42, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x11, 0x52, 0x08, 0x65, 0x78, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a,
+	0x09, 0x73, 0x6c, 0x6f, 0x74, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x18, 0x02, 0x20, 0x03, 0x28, 0x11,
+	0x52, 0x08, 0x73, 0x6c, 0x6f, 0x74, 0x44, 0x69, 0x66, 0x66, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72,
+	0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28, 0x03, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x6e, 0x66, 0x18, 0x04, 0x20, 0x03, 0x28, 0x04, 0x52, 0x04,
+	0x63, 0x6f, 0x6e, 0x66, 0x22, 0x40, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x4d, 0x69, 0x6e, 0x50, 0x75,
+	0x62, 0x50, 0x42, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x75, 0x62, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x69, 0x6e, 0x50, 0x75, 0x62, 0x12, 0x18, 0x0a, 0x07,
+	0x70, 0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70,
+	0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x2a, 0x81, 0x02, 0x0a, 0x09, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x50, 0x42, 0x12, 0x10, 0x0a, 0x0c, 0x49, 0x4e, 0x49, 0x54, 0x5f, 0x4d, 0x41, 0x50,
+	0x50, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x44, 0x44, 0x5f, 0x4d, 0x41,
+	0x50, 0x50, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x44, 0x44, 0x5f, 0x50,
+	0x52, 0x4f, 0x44, 0x55, 0x43, 0x54, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x50, 0x44, 0x5f,
+	0x50, 0x52, 0x4f, 0x44, 0x55, 0x43, 0x54, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x41, 0x44, 0x44,
+	0x5f, 0x50, 0x52, 0x49, 0x43, 0x45, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x44, 0x44, 0x5f,
+	0x50, 0x55, 0x42, 0x4c, 0x49, 0x53, 0x48, 0x45, 0x52, 0x10, 0x05, 0x12, 0x11, 0x0a, 0x0d, 0x44,
+	0x45, 0x4c, 0x5f, 0x50, 0x55, 0x42, 0x4c, 0x49, 0x53, 0x48, 0x45, 0x52, 0x10, 0x06, 0x12, 0x0d,
+	0x0a, 0x09, 0x55, 0x50, 0x44, 0x5f, 0x50, 0x52, 0x49, 0x43, 0x45, 0x10, 0x07, 0x12, 0x0d, 0x0a,
+	0x09, 0x41, 0x47, 0x47, 0x5f, 0x50, 0x52, 0x49, 0x43, 0x45, 0x10, 0x08, 0x12, 0x0e, 0x0a, 0x0a,
+	0x49, 0x4e, 0x49, 0x54, 0x5f, 0x50, 0x52, 0x49, 0x43, 0x45, 0x10, 0x09, 0x12, 0x0d, 0x0a, 0x09,
+	0x49, 0x4e, 0x49, 0x54, 0x5f, 0x54, 0x45, 0x53, 0x54, 0x10, 0x0a, 0x12, 0x0c, 0x0a, 0x08, 0x55,
+	0x50, 0x44, 0x5f, 0x54, 0x45, 0x53, 0x54, 0x10, 0x0b, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x45, 0x54,
+	0x5f, 0x4d, 0x49, 0x4e, 0x5f, 0x50, 0x55, 0x42, 0x10, 0x0c, 0x12, 0x1e, 0x0a, 0x1a, 0x55, 0x50,
+	0x44, 0x5f, 0x50, 0x52, 0x49, 0x43, 0x45, 0x5f, 0x4e, 0x4f, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x5f,
+	0x4f, 0x4e, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x0d, 0x2a, 0x42, 0x0a, 0x0d, 0x50, 0x72,
+	0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x50, 0x42, 0x12, 0x0b, 0x0a, 0x07, 0x55,
+	0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x54, 0x52, 0x41, 0x44,
+	0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x48, 0x41, 0x4c, 0x54, 0x45, 0x44, 0x10,
+	0x02, 0x12, 0x0b, 0x0a, 0x07, 0x41, 0x55, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x03, 0x42, 0x19,
+	0x5a, 0x17, 0x67, 0x6f, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x79, 0x74, 0x68, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_instruction_proto_rawDescOnce sync.Once
+	file_instruction_proto_rawDescData = file_instruction_proto_rawDesc
+)
+
+func file_instruction_proto_rawDescGZIP() []byte {
+	file_instruction_proto_rawDescOnce.Do(func() {
+		file_instruction_proto_rawDescData = protoimpl.X.CompressGZIP(file_instruction_proto_rawDescData)
+	})
+	return file_instruction_proto_rawDescData
+}
+
+var file_instruction_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_instruction_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_instruction_proto_goTypes = []interface{}{
+	(CommandPB)(0),        // 0: pyth.CommandPB
+	(PriceStatusPB)(0),    // 1: pyth.PriceStatusPB
+	(*InstructionPB)(nil), // 2: pyth.InstructionPB
+	(*AccountMetaPB)(nil), // 3: pyth.AccountMetaPB
+	(*UpdProductPB)(nil),  // 4: pyth.UpdProductPB
+	(*AttrPB)(nil),        // 5: pyth.AttrPB
+	(*PriceConfigPB)(nil), // 6: pyth.PriceConfigPB
+	(*PublisherPB)(nil),   // 7: pyth.PublisherPB
+	(*UpdPricePB)(nil),    // 8: pyth.UpdPricePB
+	(*UpdTestPB)(nil),     // 9: pyth.UpdTestPB
+	(*SetMinPubPB)(nil),   // 10: pyth.SetMinPubPB
+}
+var file_instruction_proto_depIdxs = []int32{
+	0,  // 0: pyth.InstructionPB.command:type_name -> pyth.CommandPB
+	3,  // 1: pyth.InstructionPB.accounts:type_name -> pyth.AccountMetaPB
+	4,  // 2: pyth.InstructionPB.upd_product:type_name -> pyth.UpdProductPB
+	6,  // 3: pyth.InstructionPB.add_price:type_name -> pyth.PriceConfigPB
+	7,  // 4: pyth.InstructionPB.add_publisher:type_name -> pyth.PublisherPB
+	7,  // 5: pyth.InstructionPB.del_publisher:type_name -> pyth.PublisherPB
+	8,  // 6: pyth.InstructionPB.upd_price:type_name -> pyth.UpdPricePB
+	6,  // 7: pyth.InstructionPB.init_price:type_name -> pyth.PriceConfigPB
+	9,  // 8: pyth.InstructionPB.upd_test:type_name -> pyth.UpdTestPB
+	10, // 9: pyth.InstructionPB.set_min_pub:type_name -> pyth.SetMinPubPB
+	5,  // 10: pyth.UpdProductPB.attrs:type_name -> pyth.AttrPB
+	1,  // 11: pyth.UpdPricePB.status:type_name -> pyth.PriceStatusPB
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_instruction_proto_init() }
+func file_instruction_proto_init() {
+	if File_instruction_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_instruction_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InstructionPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AccountMetaPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdProductPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttrPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PriceConfigPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PublisherPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdPricePB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdTestPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_instruction_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetMinPubPB); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_instruction_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*InstructionPB_UpdProduct)(nil),
+		(*InstructionPB_AddPrice)(nil),
+		(*InstructionPB_AddPublisher)(nil),
+		(*InstructionPB_DelPublisher)(nil),
+		(*InstructionPB_UpdPrice)(nil),
+		(*InstructionPB_InitPrice)(nil),
+		(*InstructionPB_UpdTest)(nil),
+		(*InstructionPB_SetMinPub)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_instruction_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_instruction_proto_goTypes,
+		DependencyIndexes: file_instruction_proto_depIdxs,
+		EnumInfos:         file_instruction_proto_enumTypes,
+		MessageInfos:      file_instruction_proto_msgTypes,
+	}.Build()
+	File_instruction_proto = out.File
+	file_instruction_proto_rawDesc = nil
+	file_instruction_proto_goTypes = nil
+	file_instruction_proto_depIdxs = nil
+}
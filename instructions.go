@@ -17,7 +17,19 @@ package pyth
 import (
 	"bytes"
 	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
@@ -30,6 +42,9 @@ func init() {
 }
 
 // Pyth program instructions.
+//
+// The program has no instruction to transfer authority over its accounts or itself.
+// Program upgrade authority changes are instructions of the BPF upgradeable loader, not of the Pyth program.
 const (
 	Instruction_InitMapping = int32(iota)
 	Instruction_AddMapping
@@ -44,6 +59,7 @@ const (
 	Instruction_InitTest
 	Instruction_UpdTest
 	Instruction_SetMinPub
+	Instruction_UpdPriceNoFailOnError
 	instruction_count // number of different instruction types
 )
 
@@ -76,11 +92,56 @@ func InstructionIDToName(id int32) string {
 		return "upd_test"
 	case Instruction_SetMinPub:
 		return "set_min_pub"
+	case Instruction_UpdPriceNoFailOnError:
+		return "upd_price_no_fail_on_error"
 	default:
 		return fmt.Sprintf("unsupported (%d)", id)
 	}
 }
 
+// instructionIDFromName returns the Pyth instruction type with the given name as returned by InstructionIDToName.
+func instructionIDFromName(name string) (int32, bool) {
+	for id := int32(0); id < instruction_count; id++ {
+		if InstructionIDToName(id) == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// IsGovernanceInstruction returns whether the instruction type modifies
+// the set of on-chain accounts or their configuration.
+//
+// Test instructions (init_test, upd_test) are neither governance nor data instructions.
+func IsGovernanceInstruction(cmd int32) bool {
+	switch cmd {
+	case Instruction_InitMapping,
+		Instruction_AddMapping,
+		Instruction_AddProduct,
+		Instruction_UpdProduct,
+		Instruction_AddPrice,
+		Instruction_AddPublisher,
+		Instruction_DelPublisher,
+		Instruction_SetMinPub,
+		Instruction_InitPrice:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDataInstruction returns whether the instruction type publishes or aggregates prices.
+func IsDataInstruction(cmd int32) bool {
+	switch cmd {
+	case Instruction_UpdPrice,
+		Instruction_AggPrice,
+		Instruction_UpdPriceNoFailOnError:
+		return true
+	default:
+		return false
+	}
+}
+
 type Instruction struct {
 	programKey solana.PublicKey
 	accounts   solana.AccountMetaSlice
@@ -93,6 +154,39 @@ func (inst *Instruction) ProgramID() solana.PublicKey {
 	return inst.programKey
 }
 
+// LabeledAccount is an account of an instruction together with its role.
+type LabeledAccount struct {
+	Role string // role name as returned by AccountLabels, empty if unknown
+	Meta *solana.AccountMeta
+}
+
+// LabeledAccounts returns the accounts of the instruction paired with their roles.
+//
+// Accounts beyond the canonical accounts of the instruction type have an empty role.
+func (inst *Instruction) LabeledAccounts() []LabeledAccount {
+	labels := accountLabels[inst.Header.Cmd]
+	out := make([]LabeledAccount, len(inst.accounts))
+	for i, acc := range inst.accounts {
+		out[i].Meta = acc
+		if i < len(labels) {
+			out[i].Role = labels[i]
+		}
+	}
+	return out
+}
+
+// InEnv returns whether the instruction targets the Pyth program of the given deployment.
+func (inst *Instruction) InEnv(env Env) bool {
+	return inst.programKey == env.Program
+}
+
+// Network returns the network name of the Pyth program targeted by the instruction.
+//
+// See NetworkByProgram.
+func (inst *Instruction) Network() (string, bool) {
+	return NetworkByProgram(inst.programKey)
+}
+
 func (inst *Instruction) Accounts() []*solana.AccountMeta {
 	return inst.accounts
 }
@@ -121,15 +215,330 @@ func (inst *Instruction) Data() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// DataInto appends the binary encoded instruction data to buf and returns the extended buffer.
+//
+// Behaves like Data, but allows re-using a buffer across calls.
+// Instructions without payload and price updates are encoded without any allocations
+// if buf has sufficient capacity.
+func (inst *Instruction) DataInto(buf []byte) ([]byte, error) {
+	switch payload := inst.Payload.(type) {
+	case nil:
+		return appendCommandHeader(buf, inst.Header), nil
+	case *CommandUpdPrice:
+		buf = appendCommandHeader(buf, inst.Header)
+		buf = appendUint32(buf, uint32(payload.Status))
+		buf = appendUint32(buf, payload.Unused)
+		buf = appendUint64(buf, uint64(payload.Price))
+		buf = appendUint64(buf, payload.Conf)
+		buf = appendUint64(buf, payload.PubSlot)
+		return buf, nil
+	default:
+		data, err := inst.Data()
+		if err != nil {
+			return buf, err
+		}
+		return append(buf, data...), nil
+	}
+}
+
+func appendCommandHeader(buf []byte, hdr CommandHeader) []byte {
+	buf = appendUint32(buf, hdr.Version)
+	return appendUint32(buf, uint32(hdr.Cmd))
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var tmp [4]byte
+	wireByteOrder.PutUint32(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var tmp [8]byte
+	wireByteOrder.PutUint64(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+// IsNoFailOnError returns whether the instruction is an Instruction_UpdPriceNoFailOnError.
+//
+// Unlike Instruction_UpdPrice, this variant does not fail the transaction
+// if the price update cannot be applied, e.g. when the publisher is not permissioned.
+func (inst *Instruction) IsNoFailOnError() bool {
+	return inst.Header.Cmd == Instruction_UpdPriceNoFailOnError
+}
+
+// ProductKey returns the product account referenced by the instruction.
+//
+// The product account is at a different position depending on the command:
+//   - Instruction_AddProduct: the new product account (accounts[2])
+//   - Instruction_UpdProduct: the updated product account (accounts[1])
+//   - Instruction_AddPrice: the product the new price account is attached to (accounts[1])
+//
+// Returns false for other commands.
+func (inst *Instruction) ProductKey() (solana.PublicKey, bool) {
+	switch inst.Header.Cmd {
+	case Instruction_AddProduct:
+		return inst.accountKey(2)
+	case Instruction_UpdProduct, Instruction_AddPrice:
+		return inst.accountKey(1)
+	default:
+		return solana.PublicKey{}, false
+	}
+}
+
+// MappingKey returns the mapping account referenced by the instruction.
+//
+// The mapping account is at the following position depending on the command:
+//   - Instruction_InitMapping: the new mapping account (accounts[1])
+//   - Instruction_AddMapping: the current tail mapping account (accounts[1])
+//   - Instruction_AddProduct: the mapping the new product is added to (accounts[1])
+//
+// Returns false for other commands.
+func (inst *Instruction) MappingKey() (solana.PublicKey, bool) {
+	switch inst.Header.Cmd {
+	case Instruction_InitMapping, Instruction_AddMapping, Instruction_AddProduct:
+		return inst.accountKey(1)
+	default:
+		return solana.PublicKey{}, false
+	}
+}
+
+// accountKey returns the key of the account at the given index, if it exists.
+func (inst *Instruction) accountKey(index int) (solana.PublicKey, bool) {
+	if index >= len(inst.accounts) {
+		return solana.PublicKey{}, false
+	}
+	return inst.accounts[index].PublicKey, true
+}
+
+// ErrInvalidClockAccount is returned by Instruction.ValidateAccounts
+// if an instruction does not reference the clock sysvar where required.
+var ErrInvalidClockAccount = errors.New("expected clock sysvar account")
+
+// ErrDuplicateAccount is returned by Instruction.ValidateAccounts
+// if an instruction references the same account more than once.
+var ErrDuplicateAccount = errors.New("duplicate account")
+
+// HasDuplicateAccounts returns whether the instruction references the same account key more than once.
+//
+// The Solana runtime merges duplicate account references and their flags,
+// which can cause confusing program failures.
+func (inst *Instruction) HasDuplicateAccounts() bool {
+	for i := range inst.accounts {
+		for j := 0; j < i; j++ {
+			if inst.accounts[i].PublicKey == inst.accounts[j].PublicKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AccountOverlap returns the account keys referenced by both instructions, in the order of a, without duplicates.
+//
+// Shared accounts include read-only accounts such as sysvars.
+func AccountOverlap(a, b *Instruction) []solana.PublicKey {
+	var shared []solana.PublicKey
+	for _, accA := range a.Accounts() {
+		if containsKey(shared, accA.PublicKey) {
+			continue
+		}
+		for _, accB := range b.Accounts() {
+			if accA.PublicKey == accB.PublicKey {
+				shared = append(shared, accA.PublicKey)
+				break
+			}
+		}
+	}
+	return shared
+}
+
+// containsKey returns whether the list of keys contains the given key.
+func containsKey(keys []solana.PublicKey, key solana.PublicKey) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAccounts performs additional checks on the accounts of an instruction.
+//
+// Currently verifies that no account is referenced more than once, and that
+// Instruction_UpdPrice, Instruction_UpdPriceNoFailOnError and Instruction_AggPrice
+// reference the clock sysvar as their third account.
+// These checks are not performed by DecodeInstruction.
+func (inst *Instruction) ValidateAccounts() error {
+	if inst.HasDuplicateAccounts() {
+		return fmt.Errorf("%w in %s", ErrDuplicateAccount, InstructionIDToName(inst.Header.Cmd))
+	}
+	switch inst.Header.Cmd {
+	case Instruction_UpdPrice, Instruction_UpdPriceNoFailOnError, Instruction_AggPrice:
+		if len(inst.accounts) < 3 {
+			return fmt.Errorf("%w for %s but got only %d accounts",
+				ErrInvalidClockAccount, InstructionIDToName(inst.Header.Cmd), len(inst.accounts))
+		}
+		if key := inst.accounts[2].PublicKey; key != solana.SysVarClockPubkey {
+			return fmt.Errorf("%w for %s but got %s",
+				ErrInvalidClockAccount, InstructionIDToName(inst.Header.Cmd), key)
+		}
+	}
+	return nil
+}
+
+// Account flags used in the storage format of an Instruction.
+const (
+	instructionAccountSigner   = uint8(1 << 0)
+	instructionAccountWritable = uint8(1 << 1)
+)
+
+// accountMetaFlags returns the storage format flags of an account meta.
+func accountMetaFlags(acc *solana.AccountMeta) (flags uint8) {
+	if acc.IsSigner {
+		flags |= instructionAccountSigner
+	}
+	if acc.IsWritable {
+		flags |= instructionAccountWritable
+	}
+	return
+}
+
+// MarshalBinary encodes the instruction to a compact storage format.
+//
+// The format contains the program ID, the account metas including their flags,
+// and the instruction data. It is not the same as the on-chain instruction data returned by Data.
+func (inst *Instruction) MarshalBinary() ([]byte, error) {
+	data, err := inst.Data()
+	if err != nil {
+		return nil, err
+	}
+	if len(inst.accounts) > math.MaxUint16 {
+		return nil, fmt.Errorf("too many accounts (%d)", len(inst.accounts))
+	}
+
+	buf := new(bytes.Buffer)
+	enc := bin.NewBinEncoder(buf)
+	_ = enc.WriteBytes(inst.programKey[:], false)
+	_ = enc.WriteUint16(uint16(len(inst.accounts)), wireByteOrder)
+	for _, acc := range inst.accounts {
+		_ = enc.WriteBytes(acc.PublicKey[:], false)
+		_ = enc.WriteUint8(accountMetaFlags(acc))
+	}
+	_ = enc.WriteUint32(uint32(len(data)), wireByteOrder)
+	_ = enc.WriteBytes(data, false)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an instruction previously encoded with MarshalBinary.
+//
+// The instruction data is decoded using DecodeInstruction.
+func (inst *Instruction) UnmarshalBinary(buf []byte) error {
+	dec := bin.NewBinDecoder(buf)
+	programKey, err := dec.ReadNBytes(solana.PublicKeyLength)
+	if err != nil {
+		return fmt.Errorf("failed to read program ID: %w", err)
+	}
+	numAccounts, err := dec.ReadUint16(wireByteOrder)
+	if err != nil {
+		return fmt.Errorf("failed to read number of accounts: %w", err)
+	}
+	accounts := make([]*solana.AccountMeta, numAccounts)
+	for i := range accounts {
+		key, err := dec.ReadNBytes(solana.PublicKeyLength)
+		if err != nil {
+			return fmt.Errorf("failed to read account #%d: %w", i, err)
+		}
+		flags, err := dec.ReadUint8()
+		if err != nil {
+			return fmt.Errorf("failed to read account #%d: %w", i, err)
+		}
+		accounts[i] = &solana.AccountMeta{
+			PublicKey:  solana.PublicKeyFromBytes(key),
+			IsSigner:   flags&instructionAccountSigner != 0,
+			IsWritable: flags&instructionAccountWritable != 0,
+		}
+	}
+	dataLen, err := dec.ReadUint32(wireByteOrder)
+	if err != nil {
+		return fmt.Errorf("failed to read data length: %w", err)
+	}
+	data, err := dec.ReadNBytes(int(dataLen))
+	if err != nil {
+		return fmt.Errorf("failed to read data: %w", err)
+	}
+	if rem := dec.Remaining(); rem > 0 {
+		return fmt.Errorf("found %d superfluous bytes", rem)
+	}
+
+	decoded, err := DecodeInstruction(solana.PublicKeyFromBytes(programKey), accounts, data)
+	if err != nil {
+		return err
+	}
+	*inst = *decoded
+	return nil
+}
+
 // CommandHeader is an 8-byte header at the beginning any instruction data.
 type CommandHeader struct {
 	Version uint32 // currently V2
 	Cmd     int32
 }
 
+// commandHeaderLen is the binary size of CommandHeader.
+const commandHeaderLen = 8
+
 // Valid performs basic checks on instruction data.
+//
+// Equivalent to ValidStrict.
 func (h *CommandHeader) Valid() bool {
-	return h.Version == V2 && h.Cmd >= 0 && h.Cmd < instruction_count
+	return h.ValidStrict()
+}
+
+// ValidStrict performs basic checks on instruction data, accepting only version V2.
+//
+// Use this check when the exact layout of the instruction matters, e.g. before signing.
+// A future version might reinterpret the same bytes differently.
+func (h *CommandHeader) ValidStrict() bool {
+	return h.Version == V2 && h.validCmd()
+}
+
+// ValidCompatible performs basic checks on instruction data, accepting version V2 or any higher version.
+//
+// The newer return value reports whether the version is higher than V2,
+// in which case callers should emit a warning.
+// Use this check to keep decoding when the on-chain program bumps its version
+// while keeping a compatible layout.
+// This is a tradeoff: data of a newer version with an incompatible layout will be misinterpreted.
+func (h *CommandHeader) ValidCompatible() (valid bool, newer bool) {
+	if h.Version < V2 || !h.validCmd() {
+		return false, false
+	}
+	return true, h.Version > V2
+}
+
+// MarshalBinary returns the 8-byte wire encoding of the header.
+func (h CommandHeader) MarshalBinary() ([]byte, error) {
+	return appendCommandHeader(make([]byte, 0, commandHeaderLen), h), nil
+}
+
+// ErrByteOrder is returned when decoding instruction data with a byte-swapped header.
+//
+// This usually indicates data that has been stored in big-endian byte order by mistake.
+var ErrByteOrder = errors.New("header is in big-endian byte order")
+
+// checkHeader returns an error if the header is not valid, detecting byte-swapped headers.
+func checkHeader(hdr *CommandHeader) error {
+	if hdr.Valid() {
+		return nil
+	}
+	if hdr.Version != V2 && bits.ReverseBytes32(hdr.Version) == V2 {
+		return fmt.Errorf("not a valid Pyth instruction: %w", ErrByteOrder)
+	}
+	return errors.New("not a valid Pyth instruction")
+}
+
+func (h *CommandHeader) validCmd() bool {
+	return h.Cmd >= 0 && h.Cmd < instruction_count
 }
 
 func makeCommandHeader(cmd int32) CommandHeader {
@@ -144,6 +553,47 @@ type CommandUpdProduct struct {
 	AttrsMap
 }
 
+// NewCommandUpdProduct returns a CommandUpdProduct with attributes from alternating key and value strings.
+//
+// Attributes are kept in the given order.
+// Returns an error if the number of strings is odd or if any key or value is longer than 0xFF bytes.
+func NewCommandUpdProduct(pairs ...string) (CommandUpdProduct, error) {
+	if len(pairs)%2 != 0 {
+		return CommandUpdProduct{}, fmt.Errorf("odd number of key/value strings (%d)", len(pairs))
+	}
+	var cmd CommandUpdProduct
+	for i := 0; i < len(pairs); i += 2 {
+		k, v := pairs[i], pairs[i+1]
+		if len(k) > 0xFF {
+			return CommandUpdProduct{}, fmt.Errorf("key too long (%d > 0xFF): \"%s\"", len(k), k)
+		}
+		if len(v) > 0xFF {
+			return CommandUpdProduct{}, fmt.Errorf("value too long (%d > 0xFF): \"%s\"", len(v), v)
+		}
+		cmd.Pairs = append(cmd.Pairs, [2]string{k, v})
+	}
+	return cmd, nil
+}
+
+// ToProductAccountBytes returns the attributes region of the product account after applying the update.
+//
+// The returned slice has a length of ProductAccountAttrsLen, with unused bytes set to zero.
+// The size field of the product account header would be set to ProductAccountHeaderLen + BinaryLen().
+// Please note that the on-chain program does not clear unused bytes,
+// so an actual product account may contain leftovers of previous attributes past that size.
+func (c CommandUpdProduct) ToProductAccountBytes() ([]byte, error) {
+	attrs, err := c.AttrsMap.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if len(attrs) > ProductAccountAttrsLen {
+		return nil, fmt.Errorf("attrs too long (%d > %d)", len(attrs), ProductAccountAttrsLen)
+	}
+	data := make([]byte, ProductAccountAttrsLen)
+	copy(data, attrs)
+	return data, nil
+}
+
 // CommandAddPrice is the payload of Instruction_AddPrice.
 type CommandAddPrice struct {
 	Exponent  int32
@@ -174,13 +624,56 @@ type CommandDelPublisher struct {
 
 // CommandUpdPrice is the payload of Instruction_UpdPrice or Instruction_UpdPriceNoFailOnError.
 type CommandUpdPrice struct {
-	Status  uint32
+	Status  PriceStatus
 	Unused  uint32
 	Price   int64
 	Conf    uint64
 	PubSlot uint64
 }
 
+// ErrInvalidPriceUpdate is returned when validating a malformed CommandUpdPrice.
+var ErrInvalidPriceUpdate = errors.New("invalid price update")
+
+// ValidateOption configures the checks of CommandUpdPrice.Validate.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	maxConfRatio    float64
+	hasMaxConfRatio bool
+}
+
+// WithMaxConfRatio rejects trading prices with a confidence interval wider than
+// the given fraction of the absolute price, e.g. 0.1 for 10%.
+func WithMaxConfRatio(ratio float64) ValidateOption {
+	return func(options *validateOptions) {
+		options.maxConfRatio = ratio
+		options.hasMaxConfRatio = true
+	}
+}
+
+// Validate performs basic checks on a price update before submitting it.
+//
+// The reserved Unused field must be zero and the status must be known.
+// The confidence interval of trading prices is only checked if a bound is given using WithMaxConfRatio.
+func (c CommandUpdPrice) Validate(opts ...ValidateOption) error {
+	var options validateOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if c.Unused != 0 {
+		return fmt.Errorf("%w: unused field is %d", ErrInvalidPriceUpdate, c.Unused)
+	}
+	if c.Status > PriceStatusAuction {
+		return fmt.Errorf("%w: status %s", ErrInvalidPriceUpdate, c.Status)
+	}
+	if options.hasMaxConfRatio && c.Status == PriceStatusTrading &&
+		float64(c.Conf) > options.maxConfRatio*math.Abs(float64(c.Price)) {
+		return fmt.Errorf("%w: confidence %d exceeds %g of price %d",
+			ErrInvalidPriceUpdate, c.Conf, options.maxConfRatio, c.Price)
+	}
+	return nil
+}
+
 // CommandUpdTest is the payload Instruction_UpdTest.
 type CommandUpdTest struct {
 	Exponent int32
@@ -195,6 +688,85 @@ func newInstructionDecoder(programKey solana.PublicKey) func(accounts []*solana.
 	}
 }
 
+// wireByteOrder is the byte order of all integers in Pyth instruction data.
+var wireByteOrder = binary.LittleEndian
+
+// errNotBinDecoder is returned by decodeLE when given a decoder of another encoding.
+var errNotBinDecoder = errors.New("expected bin decoder")
+
+// decodeLE decodes v from the little-endian Pyth wire format.
+//
+// Only the bin encoding of gagliardetto/binary decodes integers in little-endian by default,
+// so decoders with any other encoding (e.g. borsh) are rejected.
+// Payload types must not override the byte order using struct tags.
+func decodeLE(dec *bin.Decoder, v interface{}) error {
+	if !dec.IsBin() {
+		return errNotBinDecoder
+	}
+	return dec.Decode(v)
+}
+
+// DecodeOption configures the behavior of DecodeInstruction.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	minAccounts    int
+	hasMinAccounts bool
+	slot           uint64
+	hasSlot        bool
+	minSlot        uint64
+	lookupTables   AddressLookupTableResolver
+}
+
+// WithMinAccounts relaxes the account count check of DecodeInstruction.
+//
+// By default, the number of accounts must exactly match what the on-chain program expects.
+// With this option, only at least n accounts are required instead.
+// Useful to decode partial fixtures, such as simulated instructions lacking some accounts.
+func WithMinAccounts(n int) DecodeOption {
+	return func(o *decodeOptions) {
+		o.minAccounts = n
+		o.hasMinAccounts = true
+	}
+}
+
+// ErrTooOld is returned by DecodeInstruction if the slot of an instruction is below the minimum slot.
+//
+// See WithSlot and WithMinSlot.
+var ErrTooOld = errors.New("instruction is older than minimum slot")
+
+// WithSlot provides the slot of the transaction containing the instruction to decode.
+func WithSlot(slot uint64) DecodeOption {
+	return func(o *decodeOptions) {
+		o.slot = slot
+		o.hasSlot = true
+	}
+}
+
+// WithMinSlot makes DecodeInstruction return ErrTooOld without decoding
+// if the slot provided via WithSlot is below minSlot.
+// Has no effect if no slot is provided.
+//
+// Useful to cheaply skip historical instructions when catching up with a live chain.
+func WithMinSlot(minSlot uint64) DecodeOption {
+	return func(o *decodeOptions) {
+		o.minSlot = minSlot
+	}
+}
+
+// decodeObserver holds the func(int32, error) set by SetDecodeObserver.
+var decodeObserver atomic.Value
+
+// SetDecodeObserver registers a function that is called by DecodeInstruction on every decode attempt.
+//
+// The observer receives the instruction type, or -1 if the data is too short to contain a header,
+// and the error returned by DecodeInstruction, if any.
+// It may be called concurrently and should return quickly, e.g. by incrementing metrics.
+// Passing nil removes the observer.
+func SetDecodeObserver(observer func(cmd int32, err error)) {
+	decodeObserver.Store(observer)
+}
+
 // DecodeInstruction attempts to reconstruct a Pyth command from an on-chain instruction.
 //
 // Security
@@ -209,15 +781,30 @@ func DecodeInstruction(
 	programKey solana.PublicKey,
 	accounts []*solana.AccountMeta,
 	data []byte,
-) (*Instruction, error) {
+	opts ...DecodeOption,
+) (inst *Instruction, err error) {
+	cmd := int32(-1)
+	if observe, _ := decodeObserver.Load().(func(int32, error)); observe != nil {
+		defer func() { observe(cmd, err) }()
+	}
+
+	var options decodeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.hasSlot && options.slot < options.minSlot {
+		return nil, fmt.Errorf("%w: slot %d is below %d", ErrTooOld, options.slot, options.minSlot)
+	}
+
 	dec := bin.NewBinDecoder(data)
 
 	var hdr CommandHeader
-	if err := dec.Decode(&hdr); err != nil {
+	if err := decodeLE(dec, &hdr); err != nil {
 		return nil, fmt.Errorf("failed to decode header: %w", err)
 	}
-	if !hdr.Valid() {
-		return nil, fmt.Errorf("not a valid Pyth instruction")
+	cmd = hdr.Cmd
+	if err := checkHeader(&hdr); err != nil {
+		return nil, err
 	}
 
 	var impl interface{}
@@ -247,6 +834,7 @@ func DecodeInstruction(
 	case Instruction_AggPrice:
 		numAccounts = 3
 	case Instruction_InitPrice:
+		impl = new(CommandInitPrice)
 		numAccounts = 2
 	case Instruction_InitTest:
 		numAccounts = 2
@@ -256,34 +844,27 @@ func DecodeInstruction(
 	case Instruction_SetMinPub:
 		impl = new(CommandSetMinPub)
 		numAccounts = 2
+	case Instruction_UpdPriceNoFailOnError:
+		impl = new(CommandUpdPrice)
+		numAccounts = 3
 	default:
 		return nil, fmt.Errorf("unsupported instruction type (%d)", hdr.Cmd)
 	}
 
-	if len(accounts) != numAccounts {
+	if options.hasMinAccounts {
+		if len(accounts) < options.minAccounts {
+			return nil, fmt.Errorf("expected at least %d accounts for %s but got %d",
+				options.minAccounts, InstructionIDToName(hdr.Cmd), len(accounts))
+		}
+	} else if len(accounts) != numAccounts {
 		return nil, fmt.Errorf("expected %d accounts for %s but got %d",
 			numAccounts, InstructionIDToName(hdr.Cmd), len(accounts))
 	}
 
 	// Decode content.
 	if impl != nil {
-		if customUnmarshal, ok := impl.(encoding.BinaryUnmarshaler); ok {
-			// If method overrides UnmarshalBinary(), use that.
-			err := customUnmarshal.UnmarshalBinary(data[dec.Position():])
-			if err != nil {
-				return nil, fmt.Errorf("while unmarshaling %s: %w",
-					InstructionIDToName(hdr.Cmd), err)
-			}
-		} else {
-			// Fall back to generic LE deserializer.
-			if err := dec.Decode(impl); err != nil {
-				return nil, fmt.Errorf("failed to decode %s: %w",
-					InstructionIDToName(hdr.Cmd), err)
-			}
-			if rem := dec.Remaining(); rem > 0 {
-				return nil, fmt.Errorf("while unmarshaling %s found %d superfluous bytes",
-					InstructionIDToName(hdr.Cmd), rem)
-			}
+		if err := decodePayload(hdr.Cmd, data[dec.Position():], impl); err != nil {
+			return nil, err
 		}
 	}
 
@@ -294,3 +875,393 @@ func DecodeInstruction(
 		Payload:    impl,
 	}, nil
 }
+
+// decodePayload decodes the instruction payload following the command header into impl.
+func decodePayload(cmd int32, body []byte, impl interface{}) error {
+	if customUnmarshal, ok := impl.(encoding.BinaryUnmarshaler); ok {
+		// If method overrides UnmarshalBinary(), use that.
+		if err := customUnmarshal.UnmarshalBinary(body); err != nil {
+			return fmt.Errorf("while unmarshaling %s: %w", InstructionIDToName(cmd), err)
+		}
+		return nil
+	}
+	// Fall back to generic LE deserializer.
+	dec := bin.NewBinDecoder(body)
+	if err := decodeLE(dec, impl); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", InstructionIDToName(cmd), err)
+	}
+	if rem := dec.Remaining(); rem > 0 {
+		return fmt.Errorf("while unmarshaling %s found %d superfluous bytes", InstructionIDToName(cmd), rem)
+	}
+	return nil
+}
+
+// commandUpdPriceLen is the binary size of CommandUpdPrice.
+const commandUpdPriceLen = 32
+
+// DecodeInstructionInto decodes the header of an on-chain instruction and its payload into a caller-supplied struct.
+//
+// Behaves like DecodeInstruction, but allows re-using a payload across calls.
+// The payload must be a pointer to the payload type of the instruction (e.g. *CommandUpdPrice),
+// or nil for instructions without payload.
+// Instructions without payload and price updates are decoded without any allocations.
+func DecodeInstructionInto(
+	programKey solana.PublicKey,
+	accounts []*solana.AccountMeta,
+	data []byte,
+	payload interface{},
+) (CommandHeader, error) {
+	var hdr CommandHeader
+	if len(data) < commandHeaderLen {
+		return hdr, fmt.Errorf("failed to decode header: %w", io.ErrUnexpectedEOF)
+	}
+	hdr.Version = wireByteOrder.Uint32(data[0:4])
+	hdr.Cmd = int32(wireByteOrder.Uint32(data[4:8]))
+	if err := checkHeader(&hdr); err != nil {
+		return hdr, err
+	}
+	if numAccounts := len(accountRoles[hdr.Cmd]); len(accounts) != numAccounts {
+		return hdr, fmt.Errorf("expected %d accounts for %s but got %d",
+			numAccounts, InstructionIDToName(hdr.Cmd), len(accounts))
+	}
+	if !isPayloadOf(hdr.Cmd, payload) {
+		return hdr, fmt.Errorf("cannot decode %s into %T", InstructionIDToName(hdr.Cmd), payload)
+	}
+
+	body := data[commandHeaderLen:]
+	switch p := payload.(type) {
+	case nil:
+		return hdr, nil
+	case *CommandUpdPrice:
+		if len(body) < commandUpdPriceLen {
+			return hdr, fmt.Errorf("failed to decode %s: %w", InstructionIDToName(hdr.Cmd), io.ErrUnexpectedEOF)
+		}
+		if rem := len(body) - commandUpdPriceLen; rem > 0 {
+			return hdr, fmt.Errorf("while unmarshaling %s found %d superfluous bytes", InstructionIDToName(hdr.Cmd), rem)
+		}
+		p.Status = PriceStatus(wireByteOrder.Uint32(body[0:4]))
+		p.Unused = wireByteOrder.Uint32(body[4:8])
+		p.Price = int64(wireByteOrder.Uint64(body[8:16]))
+		p.Conf = wireByteOrder.Uint64(body[16:24])
+		p.PubSlot = wireByteOrder.Uint64(body[24:32])
+		return hdr, nil
+	default:
+		return hdr, decodePayload(hdr.Cmd, body, payload)
+	}
+}
+
+// isPayloadOf returns whether payload has the payload type of the given instruction type.
+func isPayloadOf(cmd int32, payload interface{}) bool {
+	switch payload.(type) {
+	case nil:
+		switch cmd {
+		case Instruction_InitMapping, Instruction_AddMapping, Instruction_AddProduct,
+			Instruction_AggPrice, Instruction_InitTest:
+			return true
+		}
+		return false
+	case *CommandUpdProduct:
+		return cmd == Instruction_UpdProduct
+	case *CommandAddPrice:
+		return cmd == Instruction_AddPrice
+	case *CommandAddPublisher:
+		return cmd == Instruction_AddPublisher
+	case *CommandDelPublisher:
+		return cmd == Instruction_DelPublisher
+	case *CommandUpdPrice:
+		return cmd == Instruction_UpdPrice || cmd == Instruction_UpdPriceNoFailOnError
+	case *CommandInitPrice:
+		return cmd == Instruction_InitPrice
+	case *CommandUpdTest:
+		return cmd == Instruction_UpdTest
+	case *CommandSetMinPub:
+		return cmd == Instruction_SetMinPub
+	default:
+		return false
+	}
+}
+
+// DecodeInstructionForNetwork behaves like DecodeInstruction,
+// but resolves the program key from a network name as accepted by EnvByName.
+func DecodeInstructionForNetwork(
+	network string,
+	accounts []*solana.AccountMeta,
+	data []byte,
+	opts ...DecodeOption,
+) (*Instruction, error) {
+	env, err := EnvByName(network)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeInstruction(env.Program, accounts, data, opts...)
+}
+
+// DecodeInstructionSkipping behaves like DecodeInstruction, but drops the first skip bytes of data.
+//
+// Useful to decode Pyth instructions wrapped by router programs that prepend
+// their own prefix to the instruction data, such as an 8-byte Anchor discriminator.
+func DecodeInstructionSkipping(
+	programKey solana.PublicKey,
+	accounts []*solana.AccountMeta,
+	data []byte,
+	skip int,
+	opts ...DecodeOption,
+) (*Instruction, error) {
+	if skip < 0 || skip > len(data) {
+		return nil, fmt.Errorf("cannot skip %d bytes of %d byte instruction", skip, len(data))
+	}
+	return DecodeInstruction(programKey, accounts, data[skip:], opts...)
+}
+
+// DecodeInstructionHex behaves like DecodeInstruction, but accepts hex-encoded instruction data.
+//
+// An optional "0x" prefix and surrounding whitespace are ignored.
+func DecodeInstructionHex(
+	programKey solana.PublicKey,
+	accounts []*solana.AccountMeta,
+	hexStr string,
+	opts ...DecodeOption,
+) (*Instruction, error) {
+	hexStr = strings.TrimPrefix(strings.TrimSpace(hexStr), "0x")
+	data, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex instruction data: %w", err)
+	}
+	return DecodeInstruction(programKey, accounts, data, opts...)
+}
+
+// instructionJSON is the common JSON shape of instructions exported by indexers.
+type instructionJSON struct {
+	ProgramID solana.PublicKey `json:"programId"`
+	Accounts  []struct {
+		Pubkey     solana.PublicKey `json:"pubkey"`
+		IsSigner   bool             `json:"isSigner"`
+		IsWritable bool             `json:"isWritable"`
+	} `json:"accounts"`
+	Data []byte `json:"data"` // base64
+}
+
+// DecodeInstructionFromJSON behaves like DecodeInstruction, but reads the instruction from a JSON object
+// with a base58 program ID, the list of accounts, and base64 instruction data:
+//
+//	{"programId": "...", "accounts": [{"pubkey": "...", "isSigner": true, "isWritable": true}], "data": "..."}
+func DecodeInstructionFromJSON(r io.Reader, opts ...DecodeOption) (*Instruction, error) {
+	var raw instructionJSON
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON instruction: %w", err)
+	}
+	accounts := make([]*solana.AccountMeta, len(raw.Accounts))
+	for i, acc := range raw.Accounts {
+		accounts[i] = solana.NewAccountMeta(acc.Pubkey, acc.IsWritable, acc.IsSigner)
+	}
+	return DecodeInstruction(raw.ProgramID, accounts, raw.Data, opts...)
+}
+
+// CanonicalData returns the instruction data in canonical form,
+// such that equivalent instructions built from the same inputs are byte-identical.
+//
+// Attributes of Instruction_UpdProduct are sorted, see AttrsMap.Sort,
+// and unused and padding fields of payloads are zeroed.
+// The instruction itself is not modified.
+func CanonicalData(inst *Instruction) ([]byte, error) {
+	canonical := *inst
+	switch payload := inst.Payload.(type) {
+	case *CommandUpdProduct:
+		attrs := AttrsMap{Pairs: append([][2]string(nil), payload.Pairs...)}
+		attrs.Sort()
+		canonical.Payload = &CommandUpdProduct{AttrsMap: attrs}
+	case *CommandUpdPrice:
+		update := *payload
+		update.Unused = 0
+		canonical.Payload = &update
+	case *CommandSetMinPub:
+		minPub := *payload
+		minPub.Padding = [3]byte{}
+		canonical.Payload = &minPub
+	}
+	return canonical.Data()
+}
+
+// EncodeInstructions encodes the data of multiple instructions into a single base64 string.
+//
+// Each instruction's data is prefixed with its length as a little-endian uint32.
+// Program IDs and accounts are not included, see DecodeInstructions.
+func EncodeInstructions(insts []*Instruction) (string, error) {
+	var buf []byte
+	for i, inst := range insts {
+		data, err := inst.Data()
+		if err != nil {
+			return "", fmt.Errorf("failed to encode instruction #%d: %w", i, err)
+		}
+		buf = appendUint32(buf, uint32(len(data)))
+		buf = append(buf, data...)
+	}
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// DecodeInstructions decodes instructions encoded with EncodeInstructions.
+//
+// The accounts of the i-th instruction are provided by accountsFor(i).
+func DecodeInstructions(
+	programKey solana.PublicKey,
+	accountsFor func(i int) []*solana.AccountMeta,
+	blob string,
+) ([]*Instruction, error) {
+	buf, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 instructions: %w", err)
+	}
+	var insts []*Instruction
+	for i := 0; len(buf) > 0; i++ {
+		if len(buf) < 4 {
+			return insts, fmt.Errorf("failed to decode instruction #%d: %w", i, io.ErrUnexpectedEOF)
+		}
+		size := wireByteOrder.Uint32(buf)
+		buf = buf[4:]
+		if uint64(size) > uint64(len(buf)) {
+			return insts, fmt.Errorf("failed to decode instruction #%d: %w", i, io.ErrUnexpectedEOF)
+		}
+		inst, err := DecodeInstruction(programKey, accountsFor(i), buf[:size])
+		if err != nil {
+			return insts, fmt.Errorf("failed to decode instruction #%d: %w", i, err)
+		}
+		insts = append(insts, inst)
+		buf = buf[size:]
+	}
+	return insts, nil
+}
+
+// GroupByCommand groups instructions by their command type.
+func GroupByCommand(insts []*Instruction) map[int32][]*Instruction {
+	groups := make(map[int32][]*Instruction)
+	for _, inst := range insts {
+		groups[inst.Header.Cmd] = append(groups[inst.Header.Cmd], inst)
+	}
+	return groups
+}
+
+// CountByCommandName counts instructions by their human-readable command name.
+//
+// Names are as returned by InstructionIDToName.
+func CountByCommandName(insts []*Instruction) map[string]int {
+	counts := make(map[string]int)
+	for _, inst := range insts {
+		counts[InstructionIDToName(inst.Header.Cmd)]++
+	}
+	return counts
+}
+
+// UpdateFrequency estimates the update rate of a single price feed from a window of price updates.
+//
+// The rate is derived from the number of updates and the slot gap between the first and last PubSlot.
+// Slots are converted using slotDuration, or DefaultSlotDuration if zero.
+// All instructions must be Instruction_UpdPrice or Instruction_UpdPriceNoFailOnError to the same price account.
+func UpdateFrequency(insts []*Instruction, slotDuration time.Duration) (updatesPerSecond float64, err error) {
+	if slotDuration == 0 {
+		slotDuration = DefaultSlotDuration
+	}
+	if len(insts) < 2 {
+		return 0, fmt.Errorf("need at least 2 price updates but got %d", len(insts))
+	}
+	var priceKey solana.PublicKey
+	var minSlot, maxSlot uint64
+	for i, inst := range insts {
+		payload, ok := inst.Payload.(*CommandUpdPrice)
+		if !ok {
+			return 0, fmt.Errorf("instruction #%d: not a price update: %s", i, InstructionIDToName(inst.Header.Cmd))
+		}
+		key, ok := inst.accountKey(1)
+		if !ok {
+			return 0, fmt.Errorf("instruction #%d: missing price account", i)
+		}
+		if i == 0 {
+			priceKey = key
+			minSlot, maxSlot = payload.PubSlot, payload.PubSlot
+			continue
+		}
+		if key != priceKey {
+			return 0, fmt.Errorf("instruction #%d: price account %s differs from %s", i, key, priceKey)
+		}
+		if payload.PubSlot < minSlot {
+			minSlot = payload.PubSlot
+		}
+		if payload.PubSlot > maxSlot {
+			maxSlot = payload.PubSlot
+		}
+	}
+	if maxSlot == minSlot {
+		return 0, fmt.Errorf("all price updates are in slot %d", minSlot)
+	}
+	span := time.Duration(maxSlot-minSlot) * slotDuration
+	return float64(len(insts)-1) / span.Seconds(), nil
+}
+
+// FeedUptime returns the fraction of a window of slots during which a price feed was updated at least every maxGap slots.
+//
+// The window consists of the windowSlots slots up to and including the latest PubSlot of the given price updates.
+// Each update covers its PubSlot and the following maxGap-1 slots.
+// Instructions other than Instruction_UpdPrice and Instruction_UpdPriceNoFailOnError are ignored.
+// Returns zero if there are no price updates or the window is empty.
+func FeedUptime(insts []*Instruction, windowSlots uint64, maxGap uint64) float64 {
+	var slots []uint64
+	for _, inst := range insts {
+		if payload, ok := inst.Payload.(*CommandUpdPrice); ok {
+			slots = append(slots, payload.PubSlot)
+		}
+	}
+	if len(slots) == 0 || windowSlots == 0 {
+		return 0
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+
+	// Window is [start, end).
+	end := slots[len(slots)-1] + 1
+	var start uint64
+	if end > windowSlots {
+		start = end - windowSlots
+	}
+	var covered, coveredUntil uint64
+	coveredUntil = start
+	for _, slot := range slots {
+		from, until := slot, slot+maxGap
+		if until > end {
+			until = end
+		}
+		if from < coveredUntil {
+			from = coveredUntil
+		}
+		if until > from {
+			covered += until - from
+			coveredUntil = until
+		}
+	}
+	return float64(covered) / float64(windowSlots)
+}
+
+// DecodeInstructionForensic returns the command header and remaining payload bytes of instruction data.
+//
+// Unlike DecodeInstruction, this function does not check whether the header is valid.
+// It only fails if the data is too short to contain a header.
+// Useful to inspect corrupted or adversarial instruction data.
+func DecodeInstructionForensic(data []byte) (CommandHeader, []byte, error) {
+	var hdr CommandHeader
+	if err := decodeLE(bin.NewBinDecoder(data), &hdr); err != nil {
+		return hdr, nil, fmt.Errorf("failed to decode header: %w", err)
+	}
+	return hdr, data[commandHeaderLen:], nil
+}
+
+// IsPythInstructionData returns whether data starts with a valid command header.
+//
+// Only the first 8 bytes are inspected, without allocating a decoder.
+// Useful as a cheap filter before calling DecodeInstruction, which performs the full checks.
+func IsPythInstructionData(data []byte) bool {
+	if len(data) < commandHeaderLen {
+		return false
+	}
+	hdr := CommandHeader{
+		Version: wireByteOrder.Uint32(data[0:4]),
+		Cmd:     int32(wireByteOrder.Uint32(data[4:8])),
+	}
+	return hdr.Valid()
+}
@@ -16,8 +16,15 @@ package pyth
 
 import (
 	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +41,8 @@ var (
 	caseUpdProduct []byte
 	//go:embed tests/instruction/add_price.bin
 	caseAddPrice []byte
+	//go:embed tests/instruction/init_price.bin
+	caseInitPrice []byte
 	//go:embed tests/instruction/upd_price.bin
 	caseUpdPrice []byte
 	//go:embed tests/instruction/add_publisher.bin
@@ -55,6 +64,11 @@ func TestInstruction_InitMapping(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, env.Program, actualIns.ProgramID())
+	assert.True(t, actualIns.InEnv(Devnet))
+	assert.False(t, actualIns.InEnv(Mainnet))
+	network, ok := actualIns.Network()
+	assert.True(t, ok)
+	assert.Equal(t, "devnet", network)
 	assert.Equal(t, accs, actualIns.Accounts())
 	assert.Equal(t, CommandHeader{
 		Version: V2,
@@ -75,6 +89,145 @@ func TestInstruction_InitMapping(t *testing.T) {
 	assert.Equal(t, actualIns, rebuiltIns)
 }
 
+func TestInstruction_WithMinAccounts(t *testing.T) {
+	var env = Devnet
+
+	_, err := DecodeInstruction(env.Program, nil, caseInitMapping)
+	assert.EqualError(t, err, "expected 2 accounts for init_mapping but got 0")
+
+	actualIns, err := DecodeInstruction(env.Program, nil, caseInitMapping, WithMinAccounts(0))
+	require.NoError(t, err)
+	assert.Equal(t, Instruction_InitMapping, actualIns.Header.Cmd)
+	assert.Empty(t, actualIns.Accounts())
+	_, ok := actualIns.MappingKey()
+	assert.False(t, ok)
+
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+	}
+	_, err = DecodeInstruction(env.Program, accs, caseInitMapping, WithMinAccounts(2))
+	assert.EqualError(t, err, "expected at least 2 accounts for init_mapping but got 1")
+}
+
+func TestDecodeInstructionForNetwork(t *testing.T) {
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(Devnet.Mapping).SIGNER().WRITE(),
+	}
+
+	actualIns, err := DecodeInstructionForNetwork("devnet", accs, caseInitMapping)
+	require.NoError(t, err)
+	assert.Equal(t, Devnet.Program, actualIns.ProgramID())
+	assert.Equal(t, Instruction_InitMapping, actualIns.Header.Cmd)
+
+	_, err = DecodeInstructionForNetwork("localnet", accs, caseInitMapping)
+	assert.EqualError(t, err, `unknown network "localnet"`)
+}
+
+func TestDecodeInstructionHex(t *testing.T) {
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(Devnet.Mapping).SIGNER().WRITE(),
+	}
+	expected, err := DecodeInstruction(Devnet.Program, accs, caseInitMapping)
+	require.NoError(t, err)
+
+	actual, err := DecodeInstructionHex(Devnet.Program, accs, hex.EncodeToString(caseInitMapping))
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	actual, err = DecodeInstructionHex(Devnet.Program, accs, " 0x"+hex.EncodeToString(caseInitMapping)+"\n")
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = DecodeInstructionHex(Devnet.Program, accs, "0xzz")
+	assert.EqualError(t, err, "invalid hex instruction data: encoding/hex: invalid byte: U+007A 'z'")
+}
+
+func TestDecodeInstructionFromJSON(t *testing.T) {
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(Devnet.Mapping).SIGNER().WRITE(),
+	}
+	expected, err := DecodeInstruction(Devnet.Program, accs, caseInitMapping)
+	require.NoError(t, err)
+
+	actual, err := DecodeInstructionFromJSON(strings.NewReader(`{
+		"programId": "gSbePebfvPy7tRqimPoVecS2UsBvYv46ynrzWocc92s",
+		"accounts": [
+			{"pubkey": "7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy", "isSigner": true, "isWritable": true},
+			{"pubkey": "BmA9Z6FjioHJPpjT39QazZyhDRUdZy2ezwx4GiDdE2u2", "isSigner": true, "isWritable": true}
+		],
+		"data": "` + base64.StdEncoding.EncodeToString(caseInitMapping) + `"
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = DecodeInstructionFromJSON(strings.NewReader(`{"programId": 1}`))
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid JSON instruction: "))
+}
+
+func TestDecodeInstruction_MinSlot(t *testing.T) {
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(Devnet.Mapping).SIGNER().WRITE(),
+	}
+	live := []DecodeOption{WithMinSlot(1000)}
+
+	_, err := DecodeInstruction(Devnet.Program, accs, caseInitMapping, append(live, WithSlot(1000))...)
+	assert.NoError(t, err)
+	inst, err := DecodeInstruction(Devnet.Program, accs, caseInitMapping, append(live, WithSlot(999))...)
+	assert.ErrorIs(t, err, ErrTooOld)
+	assert.EqualError(t, err, "instruction is older than minimum slot: slot 999 is below 1000")
+	assert.Nil(t, inst)
+	// Stale instructions are rejected before decoding.
+	_, err = DecodeInstruction(Devnet.Program, nil, nil, append(live, WithSlot(999))...)
+	assert.ErrorIs(t, err, ErrTooOld)
+
+	_, err = DecodeInstruction(Devnet.Program, accs, caseInitMapping, WithSlot(999))
+	assert.NoError(t, err)
+
+	// Without a slot, the minimum slot is not checked.
+	_, err = DecodeInstruction(Devnet.Program, accs, caseInitMapping, live...)
+	assert.NoError(t, err)
+	_, err = DecodeInstruction(Devnet.Program, accs, caseInitMapping, append(live, WithSlot(0))...)
+	assert.ErrorIs(t, err, ErrTooOld)
+}
+
+func TestSetDecodeObserver(t *testing.T) {
+	type observation struct {
+		cmd int32
+		err error
+	}
+	var observed []observation
+	SetDecodeObserver(func(cmd int32, err error) {
+		observed = append(observed, observation{cmd, err})
+	})
+	t.Cleanup(func() { SetDecodeObserver(nil) })
+
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(Devnet.Mapping).SIGNER().WRITE(),
+	}
+	_, err := DecodeInstruction(Devnet.Program, accs, caseInitMapping)
+	require.NoError(t, err)
+	_, errAccounts := DecodeInstruction(Devnet.Program, accs[:1], caseInitMapping)
+	require.Error(t, errAccounts)
+	_, errHeader := DecodeInstruction(Devnet.Program, accs, []byte{0x02})
+	require.Error(t, errHeader)
+
+	assert.Equal(t, []observation{
+		{Instruction_InitMapping, nil},
+		{Instruction_InitMapping, errAccounts},
+		{-1, errHeader},
+	}, observed)
+
+	SetDecodeObserver(nil)
+	_, err = DecodeInstruction(Devnet.Program, accs, caseInitMapping)
+	require.NoError(t, err)
+	assert.Len(t, observed, 3)
+}
+
 func TestInstruction_AddMapping(t *testing.T) {
 	var env = Devnet
 	var accs = []*solana.AccountMeta{
@@ -222,6 +375,41 @@ func TestInstruction_AddPrice(t *testing.T) {
 	assert.Equal(t, actualIns, rebuiltIns)
 }
 
+func TestInstruction_InitPrice(t *testing.T) {
+	var env = Devnet
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")).SIGNER().WRITE(),
+	}
+
+	actualIns, err := DecodeInstruction(env.Program, accs, caseInitPrice)
+	require.NoError(t, err)
+
+	assert.Equal(t, env.Program, actualIns.ProgramID())
+	assert.Equal(t, accs, actualIns.Accounts())
+	assert.Equal(t, CommandHeader{
+		Version: V2,
+		Cmd:     Instruction_InitPrice,
+	}, actualIns.Header)
+	assert.Equal(t, "init_price", InstructionIDToName(actualIns.Header.Cmd))
+	assert.Equal(t, &CommandInitPrice{
+		Exponent:  -5,
+		PriceType: 1,
+	}, actualIns.Payload)
+
+	data, err := actualIns.Data()
+	assert.NoError(t, err)
+	assert.Len(t, data, 16)
+	require.Equal(t, caseInitPrice, data)
+
+	rebuiltIns := NewInstructionBuilder(env.Program).InitPrice(
+		accs[0].PublicKey,
+		accs[1].PublicKey,
+		*actualIns.Payload.(*CommandInitPrice),
+	)
+	assert.Equal(t, actualIns, rebuiltIns)
+}
+
 func TestInstruction_AddPublisher(t *testing.T) {
 	var env = Devnet
 	var accs = []*solana.AccountMeta{
@@ -379,6 +567,25 @@ func TestInstruction_WrongVersion(t *testing.T) {
 	assert.Nil(t, actualIns)
 }
 
+func TestInstruction_ByteOrder(t *testing.T) {
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")).SIGNER().WRITE(),
+	}
+	data := []byte{
+		0x00, 0x00, 0x00, 0x02, // version
+		0x00, 0x00, 0x00, 0x00, // instruction type
+	}
+
+	actualIns, err := DecodeInstruction(Devnet.Program, accs, data)
+	assert.ErrorIs(t, err, ErrByteOrder)
+	assert.EqualError(t, err, "not a valid Pyth instruction: header is in big-endian byte order")
+	assert.Nil(t, actualIns)
+
+	_, err = DecodeInstructionInto(Devnet.Program, accs, data, nil)
+	assert.ErrorIs(t, err, ErrByteOrder)
+}
+
 func TestInstruction_Unsupported(t *testing.T) {
 	var env = Devnet
 	var accs = []*solana.AccountMeta{
@@ -393,3 +600,747 @@ func TestInstruction_Unsupported(t *testing.T) {
 	require.EqualError(t, err, "not a valid Pyth instruction")
 	assert.Nil(t, actualIns)
 }
+
+func TestInstruction_MarshalBinary(t *testing.T) {
+	var env = Devnet
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}
+
+	ins, err := DecodeInstruction(env.Program, accs, caseUpdPrice)
+	require.NoError(t, err)
+
+	buf, err := ins.MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, buf, 32+2+3*33+4+len(caseUpdPrice))
+
+	var reloaded Instruction
+	require.NoError(t, reloaded.UnmarshalBinary(buf))
+	assert.Equal(t, ins, &reloaded)
+
+	assert.EqualError(t, reloaded.UnmarshalBinary(append(buf, 0x00)), "found 1 superfluous bytes")
+	assert.Error(t, reloaded.UnmarshalBinary(buf[:len(buf)-1]))
+}
+
+func TestCanonicalData(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	a, err := NewCommandUpdProduct("symbol", "FX.EUR/USD", "asset_type", "FX")
+	require.NoError(t, err)
+	b, err := NewCommandUpdProduct("asset_type", "FX", "symbol", "FX.EUR/USD")
+	require.NoError(t, err)
+	instA := builder.UpdProduct(key, key, a)
+	dataA, err := CanonicalData(instA)
+	require.NoError(t, err)
+	dataB, err := CanonicalData(builder.UpdProduct(key, key, b))
+	require.NoError(t, err)
+	assert.Equal(t, dataA, dataB)
+	assert.Equal(t, "symbol", instA.Payload.(*CommandUpdProduct).Pairs[0][0], "instruction must not be modified")
+
+	updPrice := builder.UpdPrice(key, key, CommandUpdPrice{Status: PriceStatusTrading, Unused: 7, Price: 1})
+	data, err := CanonicalData(updPrice)
+	require.NoError(t, err)
+	expected, err := builder.UpdPrice(key, key, CommandUpdPrice{Status: PriceStatusTrading, Price: 1}).Data()
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+	assert.Equal(t, uint32(7), updPrice.Payload.(*CommandUpdPrice).Unused)
+
+	data, err = CanonicalData(builder.SetMinPub(key, key, CommandSetMinPub{MinPub: 3, Padding: [3]byte{1, 2, 3}}))
+	require.NoError(t, err)
+	expected, err = builder.SetMinPub(key, key, CommandSetMinPub{MinPub: 3}).Data()
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+}
+
+func TestEncodeInstructions(t *testing.T) {
+	insts := canonicalInstructions()
+	blob, err := EncodeInstructions(insts)
+	require.NoError(t, err)
+
+	decoded, err := DecodeInstructions(Devnet.Program, func(i int) []*solana.AccountMeta {
+		return insts[i].Accounts()
+	}, blob)
+	require.NoError(t, err)
+	assert.Equal(t, insts, decoded)
+
+	empty, err := EncodeInstructions(nil)
+	require.NoError(t, err)
+	decoded, err = DecodeInstructions(Devnet.Program, nil, empty)
+	require.NoError(t, err)
+	assert.Empty(t, decoded)
+
+	raw, err := base64.StdEncoding.DecodeString(blob)
+	require.NoError(t, err)
+	truncated := base64.StdEncoding.EncodeToString(raw[:len(raw)-1])
+	decoded, err = DecodeInstructions(Devnet.Program, func(i int) []*solana.AccountMeta {
+		return insts[i].Accounts()
+	}, truncated)
+	assert.EqualError(t, err, fmt.Sprintf("failed to decode instruction #%d: unexpected EOF", len(insts)-1))
+	assert.Len(t, decoded, len(insts)-1)
+
+	_, err = DecodeInstructions(Devnet.Program, nil, "!")
+	assert.EqualError(t, err, "invalid base64 instructions: illegal base64 data at input byte 0")
+}
+
+func TestUpdateFrequency(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	var insts []*Instruction
+	for _, slot := range []uint64{100, 105, 102, 110} {
+		insts = append(insts, builder.UpdPrice(publisher, priceKey, CommandUpdPrice{PubSlot: slot}))
+	}
+
+	rate, err := UpdateFrequency(insts, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.75, rate, 1e-9) // 3 updates over 10 slots of 400ms
+	rate, err = UpdateFrequency(insts, time.Second)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.3, rate, 1e-9)
+
+	_, err = UpdateFrequency(insts[:1], 0)
+	assert.EqualError(t, err, "need at least 2 price updates but got 1")
+	_, err = UpdateFrequency([]*Instruction{insts[0], insts[0]}, 0)
+	assert.EqualError(t, err, "all price updates are in slot 100")
+	_, err = UpdateFrequency([]*Instruction{insts[0], builder.UpdPrice(publisher, publisher, CommandUpdPrice{})}, 0)
+	assert.EqualError(t, err, "instruction #1: price account 5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7 differs from EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	_, err = UpdateFrequency([]*Instruction{insts[0], builder.InitMapping(publisher, priceKey)}, 0)
+	assert.EqualError(t, err, "instruction #1: not a price update: init_mapping")
+}
+
+func TestFeedUptime(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	var insts []*Instruction
+	for _, slot := range []uint64{119, 100, 102, 104, 110, 112, 114, 116, 118} {
+		insts = append(insts, builder.UpdPrice(publisher, priceKey, CommandUpdPrice{PubSlot: slot}))
+	}
+	insts = append(insts, builder.AggPrice(publisher, priceKey))
+
+	// Window is slots 100 to 119, slots 106 to 109 are not covered.
+	assert.InDelta(t, 0.8, FeedUptime(insts, 20, 2), 1e-9)
+	assert.InDelta(t, 1, FeedUptime(insts, 20, 10), 1e-9)
+	// Window is slots 90 to 119, slots before the first update are not covered.
+	assert.InDelta(t, 16.0/30, FeedUptime(insts, 30, 2), 1e-9)
+	// Window is slots 110 to 119.
+	assert.InDelta(t, 1, FeedUptime(insts, 10, 2), 1e-9)
+
+	assert.Zero(t, FeedUptime(insts, 0, 2))
+	assert.Zero(t, FeedUptime(nil, 20, 2))
+}
+
+func TestGroupByCommand(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	insts := []*Instruction{
+		builder.UpdPrice(key, key, CommandUpdPrice{}),
+		builder.AggPrice(key, key),
+		builder.UpdPrice(key, key, CommandUpdPrice{}),
+	}
+
+	groups := GroupByCommand(insts)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, []*Instruction{insts[0], insts[2]}, groups[Instruction_UpdPrice])
+	assert.Equal(t, []*Instruction{insts[1]}, groups[Instruction_AggPrice])
+
+	assert.Equal(t, map[string]int{
+		"upd_price": 2,
+		"agg_price": 1,
+	}, CountByCommandName(insts))
+}
+
+func TestInstruction_ValidateAccounts(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	funding := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	other := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+
+	ins := builder.UpdPrice(funding, key, CommandUpdPrice{})
+	assert.NoError(t, ins.ValidateAccounts())
+
+	ins.accounts[2] = solana.Meta(other)
+	err := ins.ValidateAccounts()
+	assert.ErrorIs(t, err, ErrInvalidClockAccount)
+	assert.EqualError(t, err, "expected clock sysvar account for upd_price but got EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+
+	ins = builder.AggPrice(funding, key)
+	ins.accounts = ins.accounts[:2]
+	assert.ErrorIs(t, ins.ValidateAccounts(), ErrInvalidClockAccount)
+
+	assert.NoError(t, builder.AddPublisher(funding, key, CommandAddPublisher{}).ValidateAccounts())
+}
+
+func TestInstruction_HasDuplicateAccounts(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	funding := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+
+	assert.False(t, builder.UpdPrice(funding, key, CommandUpdPrice{}).HasDuplicateAccounts())
+
+	ins := builder.UpdPrice(key, key, CommandUpdPrice{})
+	assert.True(t, ins.HasDuplicateAccounts())
+	err := ins.ValidateAccounts()
+	assert.ErrorIs(t, err, ErrDuplicateAccount)
+	assert.EqualError(t, err, "duplicate account in upd_price")
+}
+
+func TestAccountOverlap(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	pub1 := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	pub2 := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	price1 := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	price2 := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+
+	a := builder.UpdPrice(pub1, price1, CommandUpdPrice{})
+	assert.Equal(t, []solana.PublicKey{price1, solana.SysVarClockPubkey},
+		AccountOverlap(a, builder.UpdPrice(pub2, price1, CommandUpdPrice{})))
+	assert.Equal(t, []solana.PublicKey{solana.SysVarClockPubkey},
+		AccountOverlap(a, builder.UpdPrice(pub2, price2, CommandUpdPrice{})))
+	assert.Empty(t, AccountOverlap(a, builder.InitMapping(pub2, price2)))
+	// Duplicates are only reported once.
+	assert.Equal(t, []solana.PublicKey{pub1},
+		AccountOverlap(builder.InitMapping(pub1, pub1), builder.InitMapping(pub1, price2)))
+}
+
+func TestDecodeInstructionForensic(t *testing.T) {
+	hdr, rest, err := DecodeInstructionForensic([]byte{
+		0x03, 0x00, 0x00, 0x00, // version
+		0xfe, 0xff, 0x00, 0x00, // instruction type
+		0x01, 0x02,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, CommandHeader{Version: 3, Cmd: 0xfffe}, hdr)
+	assert.False(t, hdr.Valid())
+	assert.Equal(t, []byte{0x01, 0x02}, rest)
+
+	hdr, rest, err = DecodeInstructionForensic(caseUpdPrice)
+	require.NoError(t, err)
+	assert.True(t, hdr.Valid())
+	assert.Equal(t, caseUpdPrice[8:], rest)
+
+	_, _, err = DecodeInstructionForensic([]byte{0x02, 0x00, 0x00})
+	assert.Error(t, err)
+}
+
+func TestDecodeLE(t *testing.T) {
+	data := []byte{
+		0x02, 0x00, 0x00, 0x00, // version
+		0x01, 0x02, 0x00, 0x00, // instruction type
+	}
+	var hdr CommandHeader
+	require.NoError(t, decodeLE(bin.NewBinDecoder(data), &hdr))
+	assert.Equal(t, CommandHeader{Version: 2, Cmd: 0x0201}, hdr)
+
+	assert.ErrorIs(t, decodeLE(bin.NewBorshDecoder(data), &hdr), errNotBinDecoder)
+}
+
+func TestDecodeInstructionSkipping(t *testing.T) {
+	inst := NewInstructionBuilder(Devnet.Program).AggPrice(
+		solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy"),
+		solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw"),
+	)
+	data, err := inst.Data()
+	require.NoError(t, err)
+	wrapped := append([]byte{0xaf, 0xaf, 0x6d, 0x1f, 0x0d, 0x98, 0x9b, 0xed}, data...)
+
+	actual, err := DecodeInstructionSkipping(Devnet.Program, inst.Accounts(), wrapped, 8)
+	require.NoError(t, err)
+	assert.Equal(t, inst, actual)
+
+	_, err = DecodeInstructionSkipping(Devnet.Program, inst.Accounts(), wrapped, 0)
+	assert.EqualError(t, err, "not a valid Pyth instruction")
+	_, err = DecodeInstructionSkipping(Devnet.Program, inst.Accounts(), wrapped, 17)
+	assert.EqualError(t, err, "cannot skip 17 bytes of 16 byte instruction")
+}
+
+func TestInstructionBuilder_TryAddPrice(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	inst, err := builder.TryAddPrice(key, key, key, CommandAddPrice{Exponent: -8, PriceType: 1})
+	require.NoError(t, err)
+	assert.Equal(t, builder.AddPrice(key, key, key, CommandAddPrice{Exponent: -8, PriceType: 1}), inst)
+
+	_, err = builder.TryAddPrice(key, key, key, CommandAddPrice{Exponent: 0x3713, PriceType: 1})
+	assert.ErrorIs(t, err, ErrInvalidExponent)
+	assert.EqualError(t, err, "invalid exponent 14099: above maximum of 12")
+}
+
+func TestInstructionBuilder_TryInitPrice(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	inst, err := builder.TryInitPrice(key, key, CommandInitPrice{Exponent: MinExponent, PriceType: 1})
+	require.NoError(t, err)
+	assert.Equal(t, builder.InitPrice(key, key, CommandInitPrice{Exponent: MinExponent, PriceType: 1}), inst)
+
+	_, err = builder.TryInitPrice(key, key, CommandInitPrice{Exponent: -13, PriceType: 1})
+	assert.ErrorIs(t, err, ErrInvalidExponent)
+	assert.EqualError(t, err, "invalid exponent -13: below minimum of -12")
+}
+
+func TestCommandUpdPrice_Validate(t *testing.T) {
+	valid := CommandUpdPrice{Status: PriceStatusTrading, Price: 261253500000, Conf: 120500000, PubSlot: 118774432}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, CommandUpdPrice{Status: PriceStatusUnknown}.Validate())
+
+	garbage := valid
+	garbage.Unused = 0xdeadbeef
+	assert.ErrorIs(t, garbage.Validate(), ErrInvalidPriceUpdate)
+	assert.EqualError(t, garbage.Validate(), "invalid price update: unused field is 3735928559")
+
+	unknown := valid
+	unknown.Status = 7
+	assert.EqualError(t, unknown.Validate(), "invalid price update: status unsupported (7)")
+
+	wide := valid
+	wide.Conf = uint64(wide.Price)
+	assert.NoError(t, wide.Validate())
+	assert.EqualError(t, wide.Validate(WithMaxConfRatio(0.1)),
+		"invalid price update: confidence 261253500000 exceeds 0.1 of price 261253500000")
+	assert.NoError(t, valid.Validate(WithMaxConfRatio(0.1)))
+
+	negative := valid
+	negative.Price = -negative.Price
+	assert.NoError(t, negative.Validate())
+	assert.NoError(t, negative.Validate(WithMaxConfRatio(0.1)))
+	negative.Conf = uint64(-negative.Price)
+	assert.ErrorIs(t, negative.Validate(WithMaxConfRatio(0.1)), ErrInvalidPriceUpdate)
+}
+
+func TestInstructionBuilder_TryUpdPrice(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	cmd := CommandUpdPrice{Status: PriceStatusTrading, Price: 100, Conf: 1}
+
+	inst, err := builder.TryUpdPrice(key, key, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, builder.UpdPrice(key, key, cmd), inst)
+	inst, err = builder.TryUpdPriceNoFailOnError(key, key, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, builder.UpdPriceNoFailOnError(key, key, cmd), inst)
+
+	_, err = builder.TryUpdPrice(key, key, cmd, WithMaxConfRatio(0.001))
+	assert.ErrorIs(t, err, ErrInvalidPriceUpdate)
+
+	cmd.Unused = 1
+	_, err = builder.TryUpdPrice(key, key, cmd)
+	assert.ErrorIs(t, err, ErrInvalidPriceUpdate)
+	_, err = builder.TryUpdPriceNoFailOnError(key, key, cmd)
+	assert.ErrorIs(t, err, ErrInvalidPriceUpdate)
+}
+
+func TestNewCommandUpdProduct(t *testing.T) {
+	cmd, err := NewCommandUpdProduct("symbol", "FX.EUR/USD", "asset_type", "FX")
+	require.NoError(t, err)
+	assert.Equal(t, CommandUpdProduct{AttrsMap{Pairs: [][2]string{
+		{"symbol", "FX.EUR/USD"},
+		{"asset_type", "FX"},
+	}}}, cmd)
+
+	cmd, err = NewCommandUpdProduct()
+	require.NoError(t, err)
+	assert.Empty(t, cmd.Pairs)
+
+	_, err = NewCommandUpdProduct("symbol", "FX.EUR/USD", "asset_type")
+	assert.EqualError(t, err, "odd number of key/value strings (3)")
+	_, err = NewCommandUpdProduct(strings.Repeat("a", 0x100), "")
+	assert.EqualError(t, err, "key too long (256 > 0xFF): \""+strings.Repeat("a", 0x100)+"\"")
+}
+
+func TestInstructionBuilder_ClearProduct(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	funding := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	product := solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko")
+
+	inst := builder.ClearProduct(funding, product)
+	data, err := inst.Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x02, 0x00, 0x00, 0x00, // version
+		0x03, 0x00, 0x00, 0x00, // instruction type
+	}, data)
+
+	decoded, err := DecodeInstruction(Devnet.Program, inst.Accounts(), data)
+	require.NoError(t, err)
+	assert.Empty(t, decoded.Payload.(*CommandUpdProduct).Pairs)
+}
+
+func TestInstructionBuilder_AddPublishers(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	funding := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	priceKey := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	pub1 := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	pub2 := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+
+	insts, err := builder.AddPublishers(funding, priceKey, []solana.PublicKey{pub1, pub2}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*Instruction{
+		builder.AddPublisher(funding, priceKey, CommandAddPublisher{Publisher: pub1}),
+		builder.AddPublisher(funding, priceKey, CommandAddPublisher{Publisher: pub2}),
+	}, insts)
+
+	_, err = builder.AddPublishers(funding, priceKey, []solana.PublicKey{pub1, pub2, pub1}, nil)
+	assert.EqualError(t, err, "duplicate publisher 5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+
+	var current PriceAccount
+	require.NoError(t, current.UnmarshalBinary(casePriceAccount))
+	existing := current.ValidComponents()[0].Publisher
+	_, err = builder.AddPublishers(funding, priceKey, []solana.PublicKey{pub1, existing}, &current)
+	assert.EqualError(t, err, "publisher "+existing.String()+" already added to price account")
+	_, err = builder.AddPublishers(funding, priceKey, []solana.PublicKey{funding, pub2}, &current)
+	assert.NoError(t, err)
+}
+
+func TestInstructionBuilder_UpdPriceWithPriority(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	cmd := CommandUpdPrice{Status: PriceStatusTrading, Price: 100, Conf: 1}
+
+	insts := builder.UpdPriceWithPriority(key, key, cmd, 5000)
+	require.Len(t, insts, 2)
+	assert.Equal(t, solana.MustPublicKeyFromBase58("ComputeBudget111111111111111111111111111111"), insts[0].ProgramID())
+	assert.Empty(t, insts[0].Accounts())
+	data, err := insts[0].Data()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x03, 0x88, 0x13, 0, 0, 0, 0, 0, 0}, data)
+	assert.Equal(t, builder.UpdPrice(key, key, cmd), insts[1])
+}
+
+func TestInstruction_ProductKey(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	funding := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	mapping := solana.MustPublicKeyFromBase58("BmA9Z6FjioHJPpjT39QazZyhDRUdZy2ezwx4GiDdE2u2")
+	product := solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko")
+	price := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+
+	key, ok := builder.AddPrice(funding, product, price, CommandAddPrice{}).ProductKey()
+	assert.True(t, ok)
+	assert.Equal(t, product, key)
+	key, ok = builder.AddProduct(funding, mapping, product).ProductKey()
+	assert.True(t, ok)
+	assert.Equal(t, product, key)
+	key, ok = builder.AddProduct(funding, mapping, product).MappingKey()
+	assert.True(t, ok)
+	assert.Equal(t, mapping, key)
+
+	_, ok = builder.UpdPrice(funding, price, CommandUpdPrice{}).ProductKey()
+	assert.False(t, ok)
+	_, ok = builder.UpdPrice(funding, price, CommandUpdPrice{}).MappingKey()
+	assert.False(t, ok)
+}
+
+func TestCommandUpdProduct_ToProductAccountBytes(t *testing.T) {
+	var product ProductAccount
+	require.NoError(t, product.UnmarshalBinary(caseProductAccount))
+
+	data, err := CommandUpdProduct{product.Attrs}.ToProductAccountBytes()
+	require.NoError(t, err)
+	require.Len(t, data, ProductAccountAttrsLen)
+	// The product account contains leftover bytes past its size.
+	assert.Equal(t, caseProductAccount[ProductAccountHeaderLen:product.Size], data[:product.Attrs.BinaryLen()])
+	assert.Equal(t, make([]byte, ProductAccountAttrsLen-product.Attrs.BinaryLen()), data[product.Attrs.BinaryLen():])
+
+	tooLong := CommandUpdProduct{AttrsMap{Pairs: [][2]string{
+		{"a", strings.Repeat("x", 0xFF)},
+		{"b", strings.Repeat("x", 0xFF)},
+	}}}
+	_, err = tooLong.ToProductAccountBytes()
+	assert.EqualError(t, err, "attrs too long (516 > 464)")
+}
+
+func TestInstruction_UpdPriceNoFailOnError(t *testing.T) {
+	var env = Devnet
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}
+	caseData := append([]byte{}, caseUpdPrice...)
+	caseData[4] = byte(Instruction_UpdPriceNoFailOnError)
+
+	actualIns, err := DecodeInstruction(env.Program, accs, caseData)
+	require.NoError(t, err)
+
+	assert.Equal(t, CommandHeader{
+		Version: V2,
+		Cmd:     Instruction_UpdPriceNoFailOnError,
+	}, actualIns.Header)
+	assert.Equal(t, "upd_price_no_fail_on_error", InstructionIDToName(actualIns.Header.Cmd))
+	assert.True(t, actualIns.IsNoFailOnError())
+	require.Equal(t, &CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Unused:  0,
+		Price:   261253500000,
+		Conf:    120500000,
+		PubSlot: 118774432,
+	}, actualIns.Payload)
+
+	data, err := actualIns.Data()
+	assert.NoError(t, err)
+	require.Equal(t, caseData, data)
+
+	rebuiltIns := NewInstructionBuilder(env.Program).UpdPriceNoFailOnError(
+		accs[0].PublicKey,
+		accs[1].PublicKey,
+		*actualIns.Payload.(*CommandUpdPrice),
+	)
+	assert.Equal(t, actualIns, rebuiltIns)
+	assert.NoError(t, rebuiltIns.ValidateAccounts())
+
+	updPrice, err := DecodeInstruction(env.Program, accs, caseUpdPrice)
+	require.NoError(t, err)
+	assert.False(t, updPrice.IsNoFailOnError())
+}
+
+func TestInstruction_DataInto(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	insts := []*Instruction{
+		builder.UpdPrice(key, key, CommandUpdPrice{
+			Status:  PriceStatusTrading,
+			Unused:  3,
+			Price:   -261253500000,
+			Conf:    120500000,
+			PubSlot: 118774432,
+		}),
+		builder.AggPrice(key, key),
+		builder.SetMinPub(key, key, CommandSetMinPub{MinPub: 3}),
+	}
+
+	prefix := []byte{0x01, 0x02}
+	for _, ins := range insts {
+		expected, err := ins.Data()
+		require.NoError(t, err)
+		actual, err := ins.DataInto(append([]byte{}, prefix...))
+		require.NoError(t, err)
+		assert.Equal(t, append(append([]byte{}, prefix...), expected...), actual)
+	}
+}
+
+func BenchmarkInstruction_Data(b *testing.B) {
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	ins := NewInstructionBuilder(Devnet.Program).UpdPrice(key, key, CommandUpdPrice{Price: 1, Conf: 1})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ins.Data(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInstruction_DataInto(b *testing.B) {
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	ins := NewInstructionBuilder(Devnet.Program).UpdPrice(key, key, CommandUpdPrice{Price: 1, Conf: 1})
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = ins.DataInto(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestIsPythInstructionData(t *testing.T) {
+	assert.True(t, IsPythInstructionData(caseUpdPrice))
+	assert.True(t, IsPythInstructionData(caseInitMapping))
+	assert.False(t, IsPythInstructionData(caseUpdPrice[:7]))
+	assert.False(t, IsPythInstructionData(nil))
+	assert.False(t, IsPythInstructionData([]byte{
+		0x03, 0x00, 0x00, 0x00, // version
+		0x00, 0x00, 0x00, 0x00, // instruction type
+	}))
+	assert.False(t, IsPythInstructionData([]byte{
+		0x02, 0x00, 0x00, 0x00, // version
+		0xff, 0xff, 0xff, 0xff, // instruction type
+	}))
+}
+
+func BenchmarkIsPythInstructionData(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !IsPythInstructionData(caseUpdPrice) {
+			b.Fatal("expected valid instruction")
+		}
+	}
+}
+
+func BenchmarkDecodeInstruction(b *testing.B) {
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	accs := []*solana.AccountMeta{
+		solana.Meta(key).SIGNER().WRITE(),
+		solana.Meta(key).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeInstruction(Devnet.Program, accs, caseUpdPrice); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeInstructionInto(t *testing.T) {
+	for _, inst := range canonicalInstructions() {
+		t.Run(InstructionIDToName(inst.Header.Cmd), func(t *testing.T) {
+			data, err := inst.Data()
+			require.NoError(t, err)
+			expected, err := DecodeInstruction(inst.ProgramID(), inst.Accounts(), data)
+			require.NoError(t, err)
+
+			var payload interface{}
+			if expected.Payload != nil {
+				payload = reflect.New(reflect.TypeOf(expected.Payload).Elem()).Interface()
+			}
+			hdr, err := DecodeInstructionInto(inst.ProgramID(), inst.Accounts(), data, payload)
+			require.NoError(t, err)
+			assert.Equal(t, expected.Header, hdr)
+			assert.Equal(t, expected.Payload, payload)
+		})
+	}
+
+	accs := []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}
+	var updPrice CommandUpdPrice
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = DecodeInstructionInto(Devnet.Program, accs, caseUpdPrice, &updPrice)
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, int64(261253500000), updPrice.Price)
+
+	_, err := DecodeInstructionInto(Devnet.Program, accs, caseUpdPrice, &CommandSetMinPub{})
+	assert.EqualError(t, err, "cannot decode upd_price into *pyth.CommandSetMinPub")
+	_, err = DecodeInstructionInto(Devnet.Program, accs, caseUpdPrice, nil)
+	assert.EqualError(t, err, "cannot decode upd_price into <nil>")
+	_, err = DecodeInstructionInto(Devnet.Program, accs, append(caseUpdPrice[:len(caseUpdPrice):len(caseUpdPrice)], 0x00), &updPrice)
+	assert.EqualError(t, err, "while unmarshaling upd_price found 1 superfluous bytes")
+	_, err = DecodeInstructionInto(Devnet.Program, accs[:2], caseUpdPrice, &updPrice)
+	assert.EqualError(t, err, "expected 3 accounts for upd_price but got 2")
+}
+
+func BenchmarkDecodeInstructionInto(b *testing.B) {
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	accs := []*solana.AccountMeta{
+		solana.Meta(key).SIGNER().WRITE(),
+		solana.Meta(key).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}
+	var payload CommandUpdPrice
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeInstructionInto(Devnet.Program, accs, caseUpdPrice, &payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAccountsFor(t *testing.T) {
+	for _, inst := range canonicalInstructions() {
+		t.Run(InstructionIDToName(inst.Header.Cmd), func(t *testing.T) {
+			keys := make([]solana.PublicKey, len(inst.Accounts()))
+			for i, acc := range inst.Accounts() {
+				keys[i] = acc.PublicKey
+			}
+			accounts, err := AccountsFor(inst.Header.Cmd, keys...)
+			require.NoError(t, err)
+			assert.Equal(t, inst.Accounts(), accounts)
+		})
+	}
+
+	key := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	_, err := AccountsFor(Instruction_UpdPrice, key, key)
+	assert.EqualError(t, err, "expected 3 accounts for upd_price but got 2")
+	_, err = AccountsFor(-1, key)
+	assert.EqualError(t, err, "unsupported instruction type (-1)")
+}
+
+func TestAccountLabels(t *testing.T) {
+	for _, inst := range canonicalInstructions() {
+		assert.Len(t, AccountLabels(inst.Header.Cmd), len(inst.Accounts()), InstructionIDToName(inst.Header.Cmd))
+	}
+	assert.Equal(t, []string{"funding", "price", "clock"}, AccountLabels(Instruction_UpdPrice))
+	assert.Nil(t, AccountLabels(-1))
+
+	labels := AccountLabels(Instruction_UpdPrice)
+	labels[0] = "changed"
+	assert.Equal(t, "funding", AccountLabels(Instruction_UpdPrice)[0])
+}
+
+func TestInstruction_LabeledAccounts(t *testing.T) {
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	inst := NewInstructionBuilder(Devnet.Program).UpdPrice(key, key, CommandUpdPrice{})
+	labeled := inst.LabeledAccounts()
+	require.Len(t, labeled, 3)
+	assert.Equal(t, LabeledAccount{Role: "funding", Meta: inst.Accounts()[0]}, labeled[0])
+	assert.Equal(t, LabeledAccount{Role: "price", Meta: inst.Accounts()[1]}, labeled[1])
+	assert.Equal(t, LabeledAccount{Role: "clock", Meta: inst.Accounts()[2]}, labeled[2])
+
+	extra := solana.Meta(key)
+	inst.accounts = append(inst.accounts, extra)
+	assert.Equal(t, LabeledAccount{Meta: extra}, inst.LabeledAccounts()[3])
+}
+
+func TestIsGovernanceInstruction(t *testing.T) {
+	for cmd := int32(0); cmd < instruction_count; cmd++ {
+		assert.False(t, IsGovernanceInstruction(cmd) && IsDataInstruction(cmd), InstructionIDToName(cmd))
+	}
+	assert.True(t, IsGovernanceInstruction(Instruction_InitMapping))
+	assert.True(t, IsGovernanceInstruction(Instruction_SetMinPub))
+	assert.True(t, IsGovernanceInstruction(Instruction_InitPrice))
+	assert.False(t, IsGovernanceInstruction(Instruction_UpdPrice))
+	assert.False(t, IsGovernanceInstruction(Instruction_InitTest))
+	assert.False(t, IsGovernanceInstruction(instruction_count))
+
+	assert.True(t, IsDataInstruction(Instruction_UpdPrice))
+	assert.True(t, IsDataInstruction(Instruction_AggPrice))
+	assert.True(t, IsDataInstruction(Instruction_UpdPriceNoFailOnError))
+	assert.False(t, IsDataInstruction(Instruction_AddPrice))
+	assert.False(t, IsDataInstruction(Instruction_UpdTest))
+	assert.False(t, IsDataInstruction(instruction_count))
+}
+
+func TestCommandHeader_MarshalBinary(t *testing.T) {
+	hdr := makeCommandHeader(Instruction_UpdPrice)
+	buf, err := hdr.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, caseUpdPrice[:commandHeaderLen], buf)
+
+	var decoded CommandHeader
+	require.NoError(t, bin.NewBinDecoder(buf).Decode(&decoded))
+	assert.Equal(t, hdr, decoded)
+}
+
+func TestCommandHeader_Valid(t *testing.T) {
+	v2 := CommandHeader{Version: V2, Cmd: Instruction_UpdPrice}
+	assert.True(t, v2.Valid())
+	assert.True(t, v2.ValidStrict())
+	valid, newer := v2.ValidCompatible()
+	assert.True(t, valid)
+	assert.False(t, newer)
+
+	v3 := CommandHeader{Version: 3, Cmd: Instruction_UpdPrice}
+	assert.False(t, v3.Valid())
+	assert.False(t, v3.ValidStrict())
+	valid, newer = v3.ValidCompatible()
+	assert.True(t, valid)
+	assert.True(t, newer)
+
+	v1 := CommandHeader{Version: 1, Cmd: Instruction_UpdPrice}
+	assert.False(t, v1.ValidStrict())
+	valid, _ = v1.ValidCompatible()
+	assert.False(t, valid)
+
+	badCmd := CommandHeader{Version: 3, Cmd: instruction_count}
+	assert.False(t, badCmd.ValidStrict())
+	valid, newer = badCmd.ValidCompatible()
+	assert.False(t, valid)
+	assert.False(t, newer)
+}
@@ -0,0 +1,74 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// LineProtocol formats a price update as an InfluxDB line protocol point.
+//
+// The point is tagged with the publisher key, followed by the given tags in key order.
+// Fields are the price, confidence interval and status, and the publish slot is used as the timestamp:
+//
+//	measurement,publisher=<key>,<tags> price=<price>i,conf=<conf>i,status="trading" <slot>
+//
+// Returns an error for instructions other than Instruction_UpdPrice and Instruction_UpdPriceNoFailOnError.
+func (inst *Instruction) LineProtocol(measurement string, tags map[string]string) (string, error) {
+	payload, ok := inst.Payload.(*CommandUpdPrice)
+	if !ok {
+		return "", fmt.Errorf("cannot format %s instruction as price point", InstructionIDToName(inst.Header.Cmd))
+	}
+	publisher, ok := inst.accountKey(0)
+	if !ok {
+		return "", errors.New("missing publisher account")
+	}
+	if _, ok := tags["publisher"]; ok {
+		return "", errors.New("tag publisher is reserved")
+	}
+	if payload.Conf > math.MaxInt64 {
+		return "", fmt.Errorf("conf %d exceeds integer field range", payload.Conf)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(lineProtocolMeasurementEscaper.Replace(measurement))
+	sb.WriteString(",publisher=")
+	sb.WriteString(publisher.String())
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sb.WriteByte(',')
+		sb.WriteString(lineProtocolTagEscaper.Replace(key))
+		sb.WriteByte('=')
+		sb.WriteString(lineProtocolTagEscaper.Replace(tags[key]))
+	}
+	fmt.Fprintf(&sb, " price=%di,conf=%di,status=\"%s\" %d",
+		payload.Price, payload.Conf, lineProtocolStringEscaper.Replace(payload.Status.String()), payload.PubSlot)
+	return sb.String(), nil
+}
+
+// Escapers for special characters of the line protocol.
+var (
+	lineProtocolMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	lineProtocolTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	lineProtocolStringEscaper      = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
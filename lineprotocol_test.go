@@ -0,0 +1,49 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstruction_LineProtocol(t *testing.T) {
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	builder := NewInstructionBuilder(Devnet.Program)
+	inst := builder.UpdPrice(publisher, priceKey, CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Price:   261253500000,
+		Conf:    120500000,
+		PubSlot: 118774432,
+	})
+
+	line, err := inst.LineProtocol("pyth prices", map[string]string{
+		"symbol": "Crypto.BTC/USD",
+		"env":    "dev net",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `pyth\ prices,publisher=5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7,env=dev\ net,symbol=Crypto.BTC/USD `+
+		`price=261253500000i,conf=120500000i,status="trading" 118774432`, line)
+
+	_, err = inst.LineProtocol("pyth", map[string]string{"publisher": "x"})
+	assert.EqualError(t, err, "tag publisher is reserved")
+
+	_, err = builder.InitMapping(publisher, priceKey).LineProtocol("pyth", nil)
+	assert.EqualError(t, err, "cannot format init_mapping instruction as price point")
+}
@@ -0,0 +1,196 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative instruction.proto
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ToProto converts the instruction to its protobuf representation.
+//
+// The schema is defined by the "pyth.InstructionPB" message in instruction.proto.
+// Use proto.Marshal to encode the result.
+func (inst *Instruction) ToProto() (*InstructionPB, error) {
+	pb := &InstructionPB{
+		Program:  append([]byte(nil), inst.programKey[:]...),
+		Version:  inst.Header.Version,
+		Command:  CommandPB(inst.Header.Cmd),
+		Accounts: make([]*AccountMetaPB, len(inst.accounts)),
+	}
+	for i, acc := range inst.accounts {
+		pb.Accounts[i] = &AccountMetaPB{
+			Pubkey:   append([]byte(nil), acc.PublicKey[:]...),
+			Signer:   acc.IsSigner,
+			Writable: acc.IsWritable,
+		}
+	}
+
+	switch payload := inst.Payload.(type) {
+	case nil:
+	case *CommandUpdProduct:
+		attrs := make([]*AttrPB, len(payload.Pairs))
+		for i, kv := range payload.Pairs {
+			attrs[i] = &AttrPB{Key: kv[0], Value: kv[1]}
+		}
+		pb.Payload = &InstructionPB_UpdProduct{UpdProduct: &UpdProductPB{Attrs: attrs}}
+	case *CommandAddPrice:
+		pb.Payload = &InstructionPB_AddPrice{AddPrice: &PriceConfigPB{
+			Exponent:  payload.Exponent,
+			PriceType: payload.PriceType,
+		}}
+	case *CommandInitPrice:
+		pb.Payload = &InstructionPB_InitPrice{InitPrice: &PriceConfigPB{
+			Exponent:  payload.Exponent,
+			PriceType: payload.PriceType,
+		}}
+	case *CommandAddPublisher:
+		pb.Payload = &InstructionPB_AddPublisher{AddPublisher: &PublisherPB{
+			Publisher: append([]byte(nil), payload.Publisher[:]...),
+		}}
+	case *CommandDelPublisher:
+		pb.Payload = &InstructionPB_DelPublisher{DelPublisher: &PublisherPB{
+			Publisher: append([]byte(nil), payload.Publisher[:]...),
+		}}
+	case *CommandUpdPrice:
+		pb.Payload = &InstructionPB_UpdPrice{UpdPrice: &UpdPricePB{
+			Status:  PriceStatusPB(payload.Status),
+			Price:   payload.Price,
+			Conf:    payload.Conf,
+			PubSlot: payload.PubSlot,
+			Unused:  payload.Unused,
+		}}
+	case *CommandUpdTest:
+		updTest := &UpdTestPB{
+			Exponent: payload.Exponent,
+			SlotDiff: make([]int32, len(payload.SlotDiff)),
+			Price:    append([]int64(nil), payload.Price[:]...),
+			Conf:     append([]uint64(nil), payload.Conf[:]...),
+		}
+		for i, v := range payload.SlotDiff {
+			updTest.SlotDiff[i] = int32(v)
+		}
+		pb.Payload = &InstructionPB_UpdTest{UpdTest: updTest}
+	case *CommandSetMinPub:
+		setMinPub := &SetMinPubPB{MinPub: uint32(payload.MinPub)}
+		if payload.Padding != [3]byte{} {
+			setMinPub.Padding = append([]byte(nil), payload.Padding[:]...)
+		}
+		pb.Payload = &InstructionPB_SetMinPub{SetMinPub: setMinPub}
+	default:
+		return nil, fmt.Errorf("unsupported payload type %T", payload)
+	}
+	return pb, nil
+}
+
+// InstructionFromProto converts the protobuf representation back to an instruction.
+//
+// The instruction is validated the same way as by DecodeInstruction.
+func InstructionFromProto(pb *InstructionPB) (*Instruction, error) {
+	inst := &Instruction{
+		accounts: make([]*solana.AccountMeta, len(pb.GetAccounts())),
+		Header:   CommandHeader{Version: pb.GetVersion(), Cmd: int32(pb.GetCommand())},
+	}
+	if err := copyPBKey(&inst.programKey, pb.GetProgram()); err != nil {
+		return nil, err
+	}
+	for i, acc := range pb.GetAccounts() {
+		meta := &solana.AccountMeta{IsSigner: acc.GetSigner(), IsWritable: acc.GetWritable()}
+		if err := copyPBKey(&meta.PublicKey, acc.GetPubkey()); err != nil {
+			return nil, err
+		}
+		inst.accounts[i] = meta
+	}
+
+	switch payload := pb.GetPayload().(type) {
+	case nil:
+	case *InstructionPB_UpdProduct:
+		cmd := new(CommandUpdProduct)
+		for _, attr := range payload.UpdProduct.GetAttrs() {
+			cmd.Pairs = append(cmd.Pairs, [2]string{attr.GetKey(), attr.GetValue()})
+		}
+		inst.Payload = cmd
+	case *InstructionPB_AddPrice:
+		inst.Payload = &CommandAddPrice{
+			Exponent:  payload.AddPrice.GetExponent(),
+			PriceType: payload.AddPrice.GetPriceType(),
+		}
+	case *InstructionPB_InitPrice:
+		inst.Payload = &CommandInitPrice{
+			Exponent:  payload.InitPrice.GetExponent(),
+			PriceType: payload.InitPrice.GetPriceType(),
+		}
+	case *InstructionPB_AddPublisher:
+		cmd := new(CommandAddPublisher)
+		if err := copyPBKey(&cmd.Publisher, payload.AddPublisher.GetPublisher()); err != nil {
+			return nil, err
+		}
+		inst.Payload = cmd
+	case *InstructionPB_DelPublisher:
+		cmd := new(CommandDelPublisher)
+		if err := copyPBKey(&cmd.Publisher, payload.DelPublisher.GetPublisher()); err != nil {
+			return nil, err
+		}
+		inst.Payload = cmd
+	case *InstructionPB_UpdPrice:
+		inst.Payload = &CommandUpdPrice{
+			Status:  PriceStatus(payload.UpdPrice.GetStatus()),
+			Price:   payload.UpdPrice.GetPrice(),
+			Conf:    payload.UpdPrice.GetConf(),
+			PubSlot: payload.UpdPrice.GetPubSlot(),
+			Unused:  payload.UpdPrice.GetUnused(),
+		}
+	case *InstructionPB_UpdTest:
+		cmd := &CommandUpdTest{Exponent: payload.UpdTest.GetExponent()}
+		if len(payload.UpdTest.GetSlotDiff()) > len(cmd.SlotDiff) ||
+			len(payload.UpdTest.GetPrice()) > len(cmd.Price) ||
+			len(payload.UpdTest.GetConf()) > len(cmd.Conf) {
+			return nil, errors.New("too many upd_test entries")
+		}
+		for i, v := range payload.UpdTest.GetSlotDiff() {
+			cmd.SlotDiff[i] = int8(v)
+		}
+		copy(cmd.Price[:], payload.UpdTest.GetPrice())
+		copy(cmd.Conf[:], payload.UpdTest.GetConf())
+		inst.Payload = cmd
+	case *InstructionPB_SetMinPub:
+		cmd := &CommandSetMinPub{MinPub: uint8(payload.SetMinPub.GetMinPub())}
+		if len(payload.SetMinPub.GetPadding()) > len(cmd.Padding) {
+			return nil, errors.New("invalid set_min_pub padding")
+		}
+		copy(cmd.Padding[:], payload.SetMinPub.GetPadding())
+		inst.Payload = cmd
+	default:
+		return nil, fmt.Errorf("unsupported payload type %T", payload)
+	}
+
+	data, err := inst.Data()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeInstruction(inst.programKey, inst.accounts, data)
+}
+
+func copyPBKey(key *solana.PublicKey, b []byte) error {
+	if len(b) != len(key) {
+		return fmt.Errorf("invalid public key length %d", len(b))
+	}
+	copy(key[:], b)
+	return nil
+}
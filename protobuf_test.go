@@ -0,0 +1,80 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInstruction_ToProto(t *testing.T) {
+	for _, inst := range canonicalInstructions() {
+		t.Run(InstructionIDToName(inst.Header.Cmd), func(t *testing.T) {
+			pb, err := inst.ToProto()
+			require.NoError(t, err)
+			buf, err := proto.Marshal(pb)
+			require.NoError(t, err)
+
+			var pb2 InstructionPB
+			require.NoError(t, proto.Unmarshal(buf, &pb2))
+			actual, err := InstructionFromProto(&pb2)
+			require.NoError(t, err)
+			assert.Equal(t, inst, actual)
+		})
+	}
+}
+
+func TestInstructionPB_Marshal(t *testing.T) {
+	var program solana.PublicKey
+	program[0] = 0x01
+	var funding solana.PublicKey
+	funding[0] = 0x02
+
+	inst := &Instruction{
+		programKey: program,
+		accounts:   []*solana.AccountMeta{solana.Meta(funding).SIGNER().WRITE()},
+		Header:     CommandHeader{Version: 2, Cmd: Instruction_SetMinPub},
+		Payload:    &CommandSetMinPub{MinPub: 3},
+	}
+	pb, err := inst.ToProto()
+	require.NoError(t, err)
+	buf, err := proto.Marshal(pb)
+	require.NoError(t, err)
+
+	expected := append([]byte{0x0a, 0x20}, program[:]...)
+	expected = append(expected, 0x10, 0x02, 0x18, 0x0c)
+	expected = append(expected, 0x22, 0x26, 0x0a, 0x20)
+	expected = append(expected, funding[:]...)
+	expected = append(expected, 0x10, 0x01, 0x18, 0x01)
+	expected = append(expected, 0x8a, 0x01, 0x02, 0x08, 0x03)
+	assert.Equal(t, expected, buf)
+
+	// Unknown fields are skipped.
+	var pb2 InstructionPB
+	require.NoError(t, proto.Unmarshal(append(buf, 0xf8, 0x01, 0x07), &pb2))
+	pb2.ProtoReflect().SetUnknown(nil)
+	assert.True(t, proto.Equal(pb, &pb2))
+
+	pb2.Program = pb2.Program[:31]
+	_, err = InstructionFromProto(&pb2)
+	assert.EqualError(t, err, "invalid public key length 31")
+
+	_, err = (&Instruction{Payload: "foo"}).ToProto()
+	assert.EqualError(t, err, "unsupported payload type string")
+}
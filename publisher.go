@@ -0,0 +1,128 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// PublishPrice submits a new component price to a price account.
+//
+// The transaction is paid for and signed by the publisher key.
+// Returns as soon as the RPC node accepted the transaction, without waiting for confirmation.
+func (c *Client) PublishPrice(
+	ctx context.Context,
+	signer solana.PrivateKey,
+	priceKey solana.PublicKey,
+	cmd CommandUpdPrice,
+) (solana.Signature, error) {
+	publisher := signer.PublicKey()
+	ins := NewInstructionBuilder(c.Env.Program).UpdPrice(publisher, priceKey, cmd)
+	tx, err := c.newSignedTransaction(ctx, []solana.Instruction{ins}, signer)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	return c.RPC.SendTransaction(ctx, tx)
+}
+
+// ErrUnauthorized is returned when a key is not allowed to modify an account.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// SetMinPublishers sets the minimum number of publishers required to aggregate a price.
+//
+// The transaction is paid for and signed by the authority key and co-signed by the price account key,
+// as required by the on-chain program.
+// Before submitting, the price account is fetched to verify that it is a price account
+// owned by the Pyth program, which would otherwise reject the instruction.
+func (c *Client) SetMinPublishers(
+	ctx context.Context,
+	authority solana.PrivateKey,
+	price solana.PrivateKey,
+	minPub uint8,
+) (solana.Signature, error) {
+	priceKey := price.PublicKey()
+	if err := c.checkPriceAuthority(ctx, priceKey); err != nil {
+		return solana.Signature{}, err
+	}
+	ins := NewInstructionBuilder(c.Env.Program).SetMinPub(authority.PublicKey(), priceKey, CommandSetMinPub{MinPub: minPub})
+	if err := ins.ValidateAccounts(); err != nil {
+		return solana.Signature{}, err
+	}
+	tx, err := c.newSignedTransaction(ctx, []solana.Instruction{ins}, authority, price)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	return c.RPC.SendTransaction(ctx, tx)
+}
+
+// checkPriceAuthority verifies that the Pyth program accepts a signature of the given price account.
+func (c *Client) checkPriceAuthority(ctx context.Context, priceKey solana.PublicKey) error {
+	info, err := c.RPC.GetAccountInfoWithOpts(ctx, priceKey, &rpc.GetAccountInfoOpts{Commitment: rpc.CommitmentConfirmed})
+	if err != nil {
+		return fmt.Errorf("failed to get price account %s: %w", priceKey, err)
+	}
+	if owner := info.Value.Owner; owner != c.Env.Program {
+		return fmt.Errorf("%w: price account %s is owned by %s, not by program %s",
+			ErrUnauthorized, priceKey, owner, c.Env.Program)
+	}
+	if err := new(PriceAccount).UnmarshalBinary(info.Value.Data.GetBinary()); err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrUnauthorized, priceKey, err)
+	}
+	return nil
+}
+
+// newSignedTransaction builds a transaction with a recent blockhash, paid for and signed by the given key.
+//
+// Any co-signers also sign the transaction.
+func (c *Client) newSignedTransaction(
+	ctx context.Context,
+	instructions []solana.Instruction,
+	signer solana.PrivateKey,
+	cosigners ...solana.PrivateKey,
+) (*solana.Transaction, error) {
+	blockhash, err := c.RPC.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+	if blockhash == nil || blockhash.Value == nil {
+		return nil, fmt.Errorf("failed to get recent blockhash: empty response")
+	}
+
+	payer := signer.PublicKey()
+	tx, err := solana.NewTransaction(instructions, blockhash.Value.Blockhash, solana.TransactionPayer(payer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		if key == payer {
+			return &signer
+		}
+		for i := range cosigners {
+			if key == cosigners[i].PublicKey() {
+				return &cosigners[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,172 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPublisherTestServer mocks the RPC methods used to send transactions.
+//
+// Sent transactions are decoded and passed to onSend.
+func newPublisherTestServer(t *testing.T, onSend func(tx *solana.Transaction)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(wr http.ResponseWriter, req *http.Request) {
+		var call struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&call))
+
+		switch call.Method {
+		case "getLatestBlockhash":
+			_, err := wr.Write([]byte(`{
+				"jsonrpc": "2.0",
+				"id": 0,
+				"result": {
+					"context": {
+						"slot": 118773287
+					},
+					"value": {
+						"blockhash": "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N",
+						"lastValidBlockHeight": 3090
+					}
+				}
+			}`))
+			require.NoError(t, err)
+		case "getAccountInfo":
+			_, err := wr.Write([]byte(`{
+				"jsonrpc": "2.0",
+				"id": 0,
+				"result": {
+					"context": {
+						"slot": 118773287
+					},
+					"value": {
+						"data": [
+							"` + base64.StdEncoding.EncodeToString(casePriceAccount) + `",
+							"base64"
+						],
+						"executable": false,
+						"lamports": 23942400,
+						"owner": "gSbePebfvPy7tRqimPoVecS2UsBvYv46ynrzWocc92s",
+						"rentEpoch": 274
+					}
+				}
+			}`))
+			require.NoError(t, err)
+		case "sendTransaction":
+			txData, err := base64.StdEncoding.DecodeString(call.Params[0].(string))
+			require.NoError(t, err)
+			tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(txData))
+			require.NoError(t, err)
+			require.NoError(t, tx.VerifySignatures())
+			onSend(tx)
+			_, err = wr.Write([]byte(`{
+				"jsonrpc": "2.0",
+				"id": 0,
+				"result": "` + tx.Signatures[0].String() + `"
+			}`))
+			require.NoError(t, err)
+		default:
+			t.Errorf("unexpected method %s", call.Method)
+		}
+	}))
+}
+
+func TestClient_PublishPrice(t *testing.T) {
+	signer := solana.NewWallet().PrivateKey
+	priceKey := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	cmd := CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Price:   261253500000,
+		Conf:    120500000,
+		PubSlot: 118774432,
+	}
+
+	var sent *solana.Transaction
+	server := newPublisherTestServer(t, func(tx *solana.Transaction) {
+		sent = tx
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	sig, err := c.PublishPrice(context.Background(), signer, priceKey, cmd)
+	require.NoError(t, err)
+	require.NotNil(t, sent)
+
+	assert.Equal(t, sent.Signatures[0], sig)
+	assert.Equal(t, signer.PublicKey(), sent.Message.AccountKeys[0])
+	assert.Equal(t, "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N", sent.Message.RecentBlockhash.String())
+	require.Len(t, sent.Message.Instructions, 1)
+
+	compiled := sent.Message.Instructions[0]
+	accs := compiled.ResolveInstructionAccounts(&sent.Message)
+	ins, err := DecodeInstruction(Devnet.Program, accs, compiled.Data)
+	require.NoError(t, err)
+	assert.Equal(t, Instruction_UpdPrice, ins.Header.Cmd)
+	assert.Equal(t, &cmd, ins.Payload)
+}
+
+func TestClient_SetMinPublishers(t *testing.T) {
+	authority := solana.NewWallet().PrivateKey
+	price := solana.NewWallet().PrivateKey
+
+	var sent *solana.Transaction
+	server := newPublisherTestServer(t, func(tx *solana.Transaction) {
+		sent = tx
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	sig, err := c.SetMinPublishers(context.Background(), authority, price, 3)
+	require.NoError(t, err)
+	require.NotNil(t, sent)
+	assert.Equal(t, sent.Signatures[0], sig)
+	require.Len(t, sent.Signatures, 2)
+	assert.Equal(t, authority.PublicKey(), sent.Message.AccountKeys[0])
+	require.Len(t, sent.Message.Instructions, 1)
+
+	compiled := sent.Message.Instructions[0]
+	accs := compiled.ResolveInstructionAccounts(&sent.Message)
+	require.Len(t, accs, 2)
+	assert.Equal(t, solana.Meta(authority.PublicKey()).SIGNER().WRITE(), accs[0])
+	assert.Equal(t, solana.Meta(price.PublicKey()).SIGNER().WRITE(), accs[1])
+	ins, err := DecodeInstruction(Devnet.Program, accs, compiled.Data)
+	require.NoError(t, err)
+	assert.Equal(t, Instruction_SetMinPub, ins.Header.Cmd)
+	assert.Equal(t, &CommandSetMinPub{MinPub: 3}, ins.Payload)
+
+	sent = nil
+	_, err = c.SetMinPublishers(context.Background(), authority, authority, 3)
+	assert.ErrorIs(t, err, ErrDuplicateAccount)
+	assert.Nil(t, sent)
+
+	c = NewClient(Mainnet, server.URL, server.URL)
+	_, err = c.SetMinPublishers(context.Background(), authority, price, 3)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.EqualError(t, err, "unauthorized: price account "+price.PublicKey().String()+
+		" is owned by gSbePebfvPy7tRqimPoVecS2UsBvYv46ynrzWocc92s, not by program FsJ3A3u2vn5cTVofAjvy6y5kwABJAqYWpe4975bi2epH")
+	assert.Nil(t, sent)
+}
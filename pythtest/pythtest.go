@@ -0,0 +1,53 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pythtest provides test helpers for code using the Pyth client.
+package pythtest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"go.blockdaemon.com/pyth"
+)
+
+// AssertRoundTrip checks that instruction data decodes and re-encodes to the same bytes.
+//
+// The instruction is decoded with placeholder accounts, so only the instruction data is verified.
+// On mismatch, the test fails with a hex dump of both the original and re-encoded data.
+func AssertRoundTrip(t testing.TB, programKey solana.PublicKey, data []byte) {
+	t.Helper()
+	hdr, _, err := pyth.DecodeInstructionForensic(data)
+	if err != nil {
+		t.Fatalf("failed to decode instruction: %s", err)
+	}
+	accounts, err := pyth.AccountsFor(hdr.Cmd, make([]solana.PublicKey, len(pyth.AccountLabels(hdr.Cmd)))...)
+	if err != nil {
+		t.Fatalf("failed to decode instruction: %s", err)
+	}
+	inst, err := pyth.DecodeInstruction(programKey, accounts, data)
+	if err != nil {
+		t.Fatalf("failed to decode instruction: %s", err)
+	}
+	encoded, err := inst.Data()
+	if err != nil {
+		t.Fatalf("failed to encode %s instruction: %s", pyth.InstructionIDToName(hdr.Cmd), err)
+	}
+	if !bytes.Equal(data, encoded) {
+		t.Errorf("%s instruction does not round-trip\noriginal (%d bytes):\n%sre-encoded (%d bytes):\n%s",
+			pyth.InstructionIDToName(hdr.Cmd), len(data), hex.Dump(data), len(encoded), hex.Dump(encoded))
+	}
+}
@@ -0,0 +1,57 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pythtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.blockdaemon.com/pyth"
+)
+
+// recordingTB records failures of a test helper instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "tests", "instruction", "*.bin"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		AssertRoundTrip(t, pyth.Devnet.Program, data)
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "tests", "instruction", "init_mapping.bin"))
+	require.NoError(t, err)
+	rec := &recordingTB{TB: t}
+	AssertRoundTrip(rec, pyth.Devnet.Program, append(data, 0xff))
+	require.Len(t, rec.failures, 1)
+	assert.True(t, strings.HasPrefix(rec.failures[0], "init_mapping instruction does not round-trip\noriginal (9 bytes):\n"))
+}
@@ -17,10 +17,14 @@ package pyth
 import (
 	"context"
 	"encoding"
+	"errors"
 	"fmt"
+	"time"
 
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
+	"go.uber.org/zap"
 )
 
 // GetPriceAccount retrieves a price account from the blockchain.
@@ -76,6 +80,67 @@ func (c *Client) queryFor(ctx context.Context, acc encoding.BinaryUnmarshaler, k
 	return slot, acc.UnmarshalBinary(data)
 }
 
+// queryHeaderFor retrieves the first bytes of an account and decodes them into acc.
+func (c *Client) queryHeaderFor(ctx context.Context, acc interface{}, length uint64, key solana.PublicKey, commitment rpc.CommitmentType) error {
+	offset := uint64(0)
+	info, err := c.RPC.GetAccountInfoWithOpts(ctx, key, &rpc.GetAccountInfoOpts{
+		Encoding:   solana.EncodingBase64,
+		Commitment: commitment,
+		DataSlice: &rpc.DataSlice{
+			Offset: &offset,
+			Length: &length,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return bin.NewBinDecoder(info.Value.Data.GetBinary()).Decode(acc)
+}
+
+// priceAccountLink is the prefix of a price account up to its linked list pointer.
+type priceAccountLink struct {
+	AccountHeader
+	Reserved [96]byte         // PriceType through Drv2
+	Product  solana.PublicKey // ProductAccount key
+	Next     solana.PublicKey // next PriceAccount key in linked list
+}
+
+// priceAccountLinkLen is the binary size of priceAccountLink.
+const priceAccountLinkLen = 176
+
+// CountPriceAccounts returns the number of price accounts of a product.
+//
+// Follows the linked list of price accounts, only retrieving the first bytes of each account.
+func (c *Client) CountPriceAccounts(ctx context.Context, productKey solana.PublicKey, commitment rpc.CommitmentType) (int, error) {
+	var product ProductAccountHeader
+	if err := c.queryHeaderFor(ctx, &product, ProductAccountHeaderLen, productKey, commitment); err != nil {
+		return 0, fmt.Errorf("error getting product account %s: %w", productKey, err)
+	}
+	if !product.Valid() || product.AccountType != AccountTypeProduct {
+		return 0, fmt.Errorf("%s is not a product account", productKey)
+	}
+
+	// Set of accounts seen to prevent infinite loops of price account linked lists.
+	seen := make(map[solana.PublicKey]struct{})
+	next := product.FirstPrice
+	for !next.IsZero() {
+		if _, ok := seen[next]; ok {
+			break
+		}
+		seen[next] = struct{}{}
+
+		var link priceAccountLink
+		if err := c.queryHeaderFor(ctx, &link, priceAccountLinkLen, next, commitment); err != nil {
+			return len(seen) - 1, fmt.Errorf("error getting price account %s: %w", next, err)
+		}
+		if !link.Valid() || link.AccountType != AccountTypePrice {
+			return len(seen) - 1, fmt.Errorf("%s is not a price account", next)
+		}
+		next = link.Next
+	}
+	return len(seen), nil
+}
+
 // GetAllProductKeys lists all mapping accounts for product account pubkeys.
 func (c *Client) GetAllProductKeys(ctx context.Context, commitment rpc.CommitmentType) ([]solana.PublicKey, error) {
 	var products []solana.PublicKey
@@ -94,6 +159,213 @@ func (c *Client) GetAllProductKeys(ctx context.Context, commitment rpc.Commitmen
 	return products, nil
 }
 
+// IterProducts returns an iterator over all product accounts, following the mapping account list.
+//
+// Mapping accounts are fetched as the iteration reaches them,
+// and product accounts are fetched lazily in batches of AccountsBatchSize.
+// Iteration stops early if yield returns false.
+// If fetching an account fails, yield is called once more with the error, and iteration stops.
+// Each call to the iterator starts over from the root mapping account.
+func (c *Client) IterProducts(
+	ctx context.Context,
+	commitment rpc.CommitmentType,
+) func(yield func(solana.PublicKey, *ProductAccount, error) bool) {
+	return func(yield func(solana.PublicKey, *ProductAccount, error) bool) {
+		next := c.Env.Mapping
+
+		const maxAccounts = 128 // arbitrary limit on the mapping account list length
+		for i := 0; i < maxAccounts && !next.IsZero(); i++ {
+			acc, err := c.GetMappingAccount(ctx, next, commitment)
+			if err != nil {
+				yield(solana.PublicKey{}, nil, fmt.Errorf("error getting mapping account %s (#%d): %w", next, i+1, err))
+				return
+			}
+			keys := acc.ProductKeys()
+			for len(keys) > 0 {
+				// Get next block of keys from list.
+				nextKeys := keys
+				if len(nextKeys) > c.AccountsBatchSize {
+					nextKeys = nextKeys[:c.AccountsBatchSize]
+					keys = keys[c.AccountsBatchSize:]
+				} else {
+					keys = nil
+				}
+
+				var products []ProductAccountEntry
+				if err := c.getProductAccountsPage(ctx, &products, nextKeys, commitment); err != nil {
+					yield(solana.PublicKey{}, nil, err)
+					return
+				}
+				for _, product := range products {
+					if !yield(product.Pubkey, product.ProductAccount, nil) {
+						return
+					}
+				}
+			}
+			next = acc.Next
+		}
+	}
+}
+
+// ErrMappingFull is returned if no mapping account has space left for more product keys.
+var ErrMappingFull = errors.New("mapping account is full")
+
+// FindWritableMapping returns the mapping account that new products should be added to.
+//
+// Traverses the mapping account list and returns its tail.
+// If the tail is full, returns its key alongside ErrMappingFull.
+// In that case, a new mapping account needs to be chained to the tail before adding products.
+func (c *Client) FindWritableMapping(ctx context.Context, commitment rpc.CommitmentType) (solana.PublicKey, error) {
+	next := c.Env.Mapping
+
+	const maxAccounts = 128 // arbitrary limit on the mapping account list length
+	for i := 0; i < maxAccounts; i++ {
+		acc, err := c.GetMappingAccount(ctx, next, commitment)
+		if err != nil {
+			return solana.PublicKey{}, fmt.Errorf("error getting mapping account %s (#%d): %w", next, i+1, err)
+		}
+		if acc.Next.IsZero() {
+			if acc.IsFull() {
+				return next, ErrMappingFull
+			}
+			return next, nil
+		}
+		next = acc.Next
+	}
+
+	return solana.PublicKey{}, fmt.Errorf("mapping account list exceeds %d accounts", maxAccounts)
+}
+
+// ValidateAddMapping checks whether a new mapping account can be chained to prevMapping via Instruction_AddMapping.
+//
+// The previous mapping account must be the full tail of the mapping account list.
+func (c *Client) ValidateAddMapping(ctx context.Context, prevMapping, newMapping solana.PublicKey, commitment rpc.CommitmentType) error {
+	if newMapping == prevMapping {
+		return errors.New("new mapping account must differ from previous mapping account")
+	}
+	acc, err := c.GetMappingAccount(ctx, prevMapping, commitment)
+	if err != nil {
+		return fmt.Errorf("error getting mapping account %s: %w", prevMapping, err)
+	}
+	if !acc.Next.IsZero() {
+		return fmt.Errorf("mapping account %s is already linked to %s", prevMapping, acc.Next)
+	}
+	if !acc.IsFull() {
+		return fmt.Errorf("mapping account %s is not full (%d products)", prevMapping, acc.Num)
+	}
+	return nil
+}
+
+// IsSetMinPubNoOp returns whether Instruction_SetMinPub with the given minimum number of publishers
+// would leave the price account unchanged.
+func (c *Client) IsSetMinPubNoOp(ctx context.Context, price solana.PublicKey, minPub uint8, commitment rpc.CommitmentType) (bool, error) {
+	acc, err := c.GetPriceAccount(ctx, price, commitment)
+	if err != nil {
+		return false, fmt.Errorf("error getting price account %s: %w", price, err)
+	}
+	return acc.MinPublishers() == minPub, nil
+}
+
+// CheckExponentConsistency returns whether a new price account with the given exponent
+// would match the exponents of all existing price accounts of a product.
+//
+// Mismatching exponents are logged as a warning.
+// Returns true if the product has no price accounts yet.
+func (c *Client) CheckExponentConsistency(
+	ctx context.Context,
+	product solana.PublicKey,
+	newExponent int32,
+	commitment rpc.CommitmentType,
+) (bool, error) {
+	acc, err := c.GetProductAccount(ctx, product, commitment)
+	if err != nil {
+		return false, fmt.Errorf("error getting product account %s: %w", product, err)
+	}
+	if acc.FirstPrice.IsZero() {
+		return true, nil
+	}
+	prices, err := c.GetPriceAccountsRecursive(ctx, commitment, acc.FirstPrice)
+	if err != nil {
+		return false, err
+	}
+	consistent := true
+	for _, price := range prices {
+		if price.Exponent() != newExponent {
+			c.Log.Warn("Exponent differs from existing price account of product",
+				zap.Stringer("product", product),
+				zap.Stringer("price", price.Pubkey),
+				zap.Int32("exponent", price.Exponent()),
+				zap.Int32("new_exponent", newExponent))
+			consistent = false
+		}
+	}
+	return consistent, nil
+}
+
+// DecodeAndFormatUpdPrice decodes the data of a price update to the given price account
+// and returns its price and confidence interval after applying the exponent of the price account.
+//
+// The data must be of Instruction_UpdPrice or Instruction_UpdPriceNoFailOnError.
+// The exponent is fetched at the given commitment level and cached by the client for ExponentCacheTTL.
+// Since init_price may re-initialize a price account with a different exponent,
+// call InvalidateExponent when observing such an instruction.
+func (c *Client) DecodeAndFormatUpdPrice(
+	ctx context.Context,
+	priceKey solana.PublicKey,
+	data []byte,
+	commitment rpc.CommitmentType,
+) (price, conf float64, err error) {
+	hdr, body, err := DecodeInstructionForensic(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := checkHeader(&hdr); err != nil {
+		return 0, 0, err
+	}
+	if hdr.Cmd != Instruction_UpdPrice && hdr.Cmd != Instruction_UpdPriceNoFailOnError {
+		return 0, 0, fmt.Errorf("not a price update: %s", InstructionIDToName(hdr.Cmd))
+	}
+	var payload CommandUpdPrice
+	if err := decodePayload(hdr.Cmd, body, &payload); err != nil {
+		return 0, 0, err
+	}
+
+	exponent, err := c.priceExponent(ctx, priceKey, commitment)
+	if err != nil {
+		return 0, 0, err
+	}
+	info := PriceInfo{Price: payload.Price, Conf: payload.Conf}
+	priceDec, confDec, _ := info.Value(exponent)
+	price, _ = priceDec.Float64()
+	conf, _ = confDec.Float64()
+	return price, conf, nil
+}
+
+// priceExponent returns the exponent of a price account, using the cache of DecodeAndFormatUpdPrice.
+func (c *Client) priceExponent(ctx context.Context, priceKey solana.PublicKey, commitment rpc.CommitmentType) (int32, error) {
+	if c.ExponentCacheTTL <= 0 {
+		c.exponents.Delete(priceKey)
+	} else if cached, ok := c.exponents.Load(priceKey); ok && time.Now().Before(cached.(*cachedExponent).expires) {
+		return cached.(*cachedExponent).exponent, nil
+	}
+	acc, err := c.GetPriceAccount(ctx, priceKey, commitment)
+	if err != nil {
+		return 0, fmt.Errorf("error getting price account %s: %w", priceKey, err)
+	}
+	if c.ExponentCacheTTL > 0 {
+		c.exponents.Store(priceKey, &cachedExponent{
+			exponent: acc.Exponent(),
+			expires:  time.Now().Add(c.ExponentCacheTTL),
+		})
+	}
+	return acc.Exponent(), nil
+}
+
+// InvalidateExponent removes the cached exponent of a price account, see DecodeAndFormatUpdPrice.
+func (c *Client) InvalidateExponent(priceKey solana.PublicKey) {
+	c.exponents.Delete(priceKey)
+}
+
 // GetAllProductAccounts returns all product accounts.
 //
 // Aborts and returns an error if any product account failed to fetch.
@@ -138,6 +410,9 @@ func (c *Client) getProductAccountsPage(
 	}
 
 	for i, info := range res.Value {
+		if info == nil {
+			return fmt.Errorf("product account %s not found", keys[i])
+		}
 		accountData := info.Data.GetBinary()
 		acc := new(ProductAccount)
 		if err := acc.UnmarshalBinary(accountData); err != nil {
@@ -174,6 +449,53 @@ func (c *Client) GetAllPriceAccounts(ctx context.Context, commitment rpc.Commitm
 	return c.GetPriceAccountsRecursive(ctx, commitment, keys...)
 }
 
+// maxMultipleAccounts is the maximum number of accounts per getMultipleAccounts call accepted by RPC nodes.
+const maxMultipleAccounts = 100
+
+// GetPriceAccounts retrieves the price accounts of the given public keys using batched getMultipleAccounts calls.
+//
+// Results are returned in input order, with nil for accounts that do not exist.
+// Batches are sized by AccountsBatchSize, up to 100 accounts per call.
+func (c *Client) GetPriceAccounts(ctx context.Context, keys []solana.PublicKey, commitment rpc.CommitmentType) ([]*PriceAccount, error) {
+	batchSize := c.AccountsBatchSize
+	if batchSize <= 0 || batchSize > maxMultipleAccounts {
+		batchSize = maxMultipleAccounts
+	}
+
+	accs := make([]*PriceAccount, 0, len(keys))
+	for len(keys) > 0 {
+		// Get next block of keys from list.
+		nextKeys := keys
+		if len(nextKeys) > batchSize {
+			nextKeys = nextKeys[:batchSize]
+			keys = keys[batchSize:]
+		} else {
+			keys = nil
+		}
+
+		res, err := c.RPC.GetMultipleAccountsWithOpts(ctx, nextKeys, &rpc.GetMultipleAccountsOpts{Commitment: commitment})
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Value) != len(nextKeys) {
+			return nil, fmt.Errorf("unexpected number of price accounts, asked for %d but got %d", len(nextKeys), len(res.Value))
+		}
+		for i, info := range res.Value {
+			if info == nil {
+				accs = append(accs, nil)
+				continue
+			}
+			acc := new(PriceAccount)
+			if err := acc.UnmarshalBinary(info.Data.GetBinary()); err != nil {
+				return nil, fmt.Errorf("failed to retrieve price account %s: %w", nextKeys[i], err)
+			}
+			accs = append(accs, acc)
+		}
+	}
+
+	return accs, nil
+}
+
 // GetPriceAccountsRecursive retrieves the price accounts of the given public keys.
 //
 // If these price accounts have successors, their contents will be fetched as well, recursively.
@@ -323,3 +323,344 @@ func TestClient_GetMappingAccount_NotFound(t *testing.T) {
 	)
 	assert.EqualError(t, err, "not found")
 }
+
+// newAccountsTestServer mocks the getAccountInfo and getMultipleAccounts RPC methods
+// serving the provided account data. Unknown accounts are reported as not found.
+func newAccountsTestServer(t *testing.T, accounts map[solana.PublicKey][]byte) *httptest.Server {
+	var opts struct {
+		DataSlice *struct {
+			Offset int `json:"offset"`
+			Length int `json:"length"`
+		} `json:"dataSlice"`
+	}
+	encodeAccount := func(key string) interface{} {
+		data, ok := accounts[solana.MustPublicKeyFromBase58(key)]
+		if !ok {
+			return nil
+		}
+		if opts.DataSlice != nil {
+			data = data[opts.DataSlice.Offset : opts.DataSlice.Offset+opts.DataSlice.Length]
+		}
+		return map[string]interface{}{
+			"data":       []string{base64.StdEncoding.EncodeToString(data), "base64"},
+			"executable": false,
+			"lamports":   23942400,
+			"owner":      Devnet.Program.String(),
+			"rentEpoch":  274,
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(func(wr http.ResponseWriter, req *http.Request) {
+		var call struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&call))
+		opts.DataSlice = nil
+		if len(call.Params) > 1 {
+			require.NoError(t, json.Unmarshal(call.Params[1], &opts))
+		}
+
+		var value interface{}
+		switch call.Method {
+		case "getAccountInfo":
+			var key string
+			require.NoError(t, json.Unmarshal(call.Params[0], &key))
+			value = encodeAccount(key)
+		case "getMultipleAccounts":
+			var keys []string
+			require.NoError(t, json.Unmarshal(call.Params[0], &keys))
+			values := make([]interface{}, len(keys))
+			for i, key := range keys {
+				values[i] = encodeAccount(key)
+			}
+			value = values
+		default:
+			t.Errorf("unexpected method %s", call.Method)
+		}
+
+		require.NoError(t, json.NewEncoder(wr).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      0,
+			"result": map[string]interface{}{
+				"context": map[string]interface{}{"slot": 118773287},
+				"value":   value,
+			},
+		}))
+	}))
+}
+
+func TestClient_IterProducts(t *testing.T) {
+	var mapping MappingAccount
+	require.NoError(t, mapping.UnmarshalBinary(caseMappingAccount))
+	accounts := map[solana.PublicKey][]byte{
+		Devnet.Mapping: caseMappingAccount,
+	}
+	for _, key := range mapping.ProductKeys() {
+		accounts[key] = caseProductAccount
+	}
+	server := newAccountsTestServer(t, accounts)
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	seq := c.IterProducts(context.Background(), rpc.CommitmentProcessed)
+	var keys []solana.PublicKey
+	seq(func(key solana.PublicKey, product *ProductAccount, err error) bool {
+		require.NoError(t, err)
+		keys = append(keys, key)
+		assert.Equal(t, "FX.EUR/USD", product.Attrs.KVs()["symbol"])
+		return true
+	})
+	assert.Equal(t, mapping.ProductKeys(), keys)
+
+	// Stop early.
+	var n int
+	seq(func(_ solana.PublicKey, _ *ProductAccount, err error) bool {
+		require.NoError(t, err)
+		n++
+		return n < 40
+	})
+	assert.Equal(t, 40, n)
+
+	// Missing product account.
+	delete(accounts, mapping.Products[1])
+	n = 0
+	var errs []error
+	seq(func(_ solana.PublicKey, _ *ProductAccount, err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			n++
+		}
+		return true
+	})
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], fmt.Sprintf("product account %s not found", mapping.Products[1]))
+	assert.Equal(t, 0, n)
+
+	// Each iteration starts over.
+	accounts[mapping.Products[1]] = caseProductAccount
+	n = 0
+	seq(func(_ solana.PublicKey, _ *ProductAccount, err error) bool {
+		require.NoError(t, err)
+		n++
+		return true
+	})
+	assert.Equal(t, len(mapping.ProductKeys()), n)
+}
+
+func TestClient_FindWritableMapping(t *testing.T) {
+	server := newAccountsTestServer(t, map[solana.PublicKey][]byte{
+		Devnet.Mapping: caseMappingAccount,
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	key, err := c.FindWritableMapping(context.Background(), rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.Equal(t, Devnet.Mapping, key)
+}
+
+func TestClient_FindWritableMapping_Full(t *testing.T) {
+	fullMapping := append([]byte{}, caseMappingAccount...)
+	fullMapping[16] = 0x80 // Num = 640
+	fullMapping[17] = 0x02
+
+	server := newAccountsTestServer(t, map[solana.PublicKey][]byte{
+		Devnet.Mapping: fullMapping,
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	key, err := c.FindWritableMapping(context.Background(), rpc.CommitmentProcessed)
+	assert.ErrorIs(t, err, ErrMappingFull)
+	assert.Equal(t, Devnet.Mapping, key)
+}
+
+func TestClient_ValidateAddMapping(t *testing.T) {
+	newMapping := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	linkedKey := solana.MustPublicKeyFromBase58("AFmdnt9ng1uVxqCmqwQJDAYC5cKTkw8gJKSM5PnzuF6z")
+	fullKey := solana.MustPublicKeyFromBase58("AHtgzX45WTKfkPG53L6WYhGEXwQkN1BVknET3sVsLL8J")
+
+	fullMapping := append([]byte{}, caseMappingAccount...)
+	fullMapping[16] = 0x80 // Num = 640
+	fullMapping[17] = 0x02
+	linkedMapping := append([]byte{}, fullMapping...)
+	copy(linkedMapping[24:56], newMapping[:])
+
+	server := newAccountsTestServer(t, map[solana.PublicKey][]byte{
+		Devnet.Mapping: caseMappingAccount,
+		linkedKey:      linkedMapping,
+		fullKey:        fullMapping,
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	ctx := context.Background()
+	assert.NoError(t, c.ValidateAddMapping(ctx, fullKey, newMapping, rpc.CommitmentProcessed))
+	assert.EqualError(t, c.ValidateAddMapping(ctx, Devnet.Mapping, newMapping, rpc.CommitmentProcessed),
+		"mapping account BmA9Z6FjioHJPpjT39QazZyhDRUdZy2ezwx4GiDdE2u2 is not full (66 products)")
+	assert.EqualError(t, c.ValidateAddMapping(ctx, linkedKey, newMapping, rpc.CommitmentProcessed),
+		"mapping account AFmdnt9ng1uVxqCmqwQJDAYC5cKTkw8gJKSM5PnzuF6z is already linked to 7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	assert.EqualError(t, c.ValidateAddMapping(ctx, fullKey, fullKey, rpc.CommitmentProcessed),
+		"new mapping account must differ from previous mapping account")
+}
+
+func TestClient_GetPriceAccounts(t *testing.T) {
+	firstPriceKey := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	secondPriceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	missingKey := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	secondPrice := append([]byte{}, casePriceAccount...)
+	secondPrice[20] = 0xF8 // Expo = -8
+
+	server := newAccountsTestServer(t, map[solana.PublicKey][]byte{
+		firstPriceKey:  casePriceAccount,
+		secondPriceKey: secondPrice,
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	c.AccountsBatchSize = 2
+	accs, err := c.GetPriceAccounts(context.Background(), []solana.PublicKey{
+		secondPriceKey,
+		missingKey,
+		firstPriceKey,
+	}, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	require.Len(t, accs, 3)
+	assert.Equal(t, int32(-8), accs[0].Exponent())
+	assert.Nil(t, accs[1])
+	assert.Equal(t, &priceAccount_E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh, accs[2])
+}
+
+func TestClient_IsSetMinPubNoOp(t *testing.T) {
+	priceKey := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	missingKey := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	server := newAccountsTestServer(t, map[solana.PublicKey][]byte{
+		priceKey: casePriceAccount,
+	})
+	defer server.Close()
+
+	var acc PriceAccount
+	require.NoError(t, acc.UnmarshalBinary(casePriceAccount))
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	ctx := context.Background()
+	noOp, err := c.IsSetMinPubNoOp(ctx, priceKey, acc.MinPublishers(), rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.True(t, noOp)
+	noOp, err = c.IsSetMinPubNoOp(ctx, priceKey, acc.MinPublishers()+1, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.False(t, noOp)
+
+	_, err = c.IsSetMinPubNoOp(ctx, missingKey, 1, rpc.CommitmentProcessed)
+	assert.EqualError(t, err, "error getting price account 7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy: not found")
+}
+
+func TestClient_CheckExponentConsistency(t *testing.T) {
+	productKey := solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko")
+	emptyProductKey := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	priceKey := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+
+	emptyProduct := append([]byte{}, caseProductAccount...)
+	copy(emptyProduct[16:48], make([]byte, 32)) // no first price account
+
+	server := newAccountsTestServer(t, map[solana.PublicKey][]byte{
+		productKey:      caseProductAccount,
+		emptyProductKey: emptyProduct,
+		priceKey:        casePriceAccount,
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	ctx := context.Background()
+	ok, err := c.CheckExponentConsistency(ctx, productKey, -5, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = c.CheckExponentConsistency(ctx, productKey, -8, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = c.CheckExponentConsistency(ctx, emptyProductKey, -8, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestClient_DecodeAndFormatUpdPrice(t *testing.T) {
+	priceKey := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	accounts := map[solana.PublicKey][]byte{
+		priceKey: casePriceAccount,
+	}
+	server := newAccountsTestServer(t, accounts)
+	defer server.Close()
+
+	builder := NewInstructionBuilder(Devnet.Program)
+	data, err := builder.UpdPrice(priceKey, priceKey, CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Price:   261253500000,
+		Conf:    120500000,
+		PubSlot: 118774432,
+	}).Data()
+	require.NoError(t, err)
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	ctx := context.Background()
+	price, conf, err := c.DecodeAndFormatUpdPrice(ctx, priceKey, data, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.InDelta(t, 2612535, price, 1e-9)
+	assert.InDelta(t, 1205, conf, 1e-9)
+
+	// Exponent is cached.
+	delete(accounts, priceKey)
+	price, _, err = c.DecodeAndFormatUpdPrice(ctx, priceKey, data, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.InDelta(t, 2612535, price, 1e-9)
+
+	// init_price re-initializes the account with a different exponent.
+	reinitialized := append([]byte{}, casePriceAccount...)
+	copy(reinitialized[20:24], []byte{0xfd, 0xff, 0xff, 0xff}) // exponent -3
+	accounts[priceKey] = reinitialized
+	c.InvalidateExponent(priceKey)
+	price, _, err = c.DecodeAndFormatUpdPrice(ctx, priceKey, data, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.InDelta(t, 261253500, price, 1e-9)
+
+	// Cached exponents expire.
+	accounts[priceKey] = casePriceAccount
+	c.ExponentCacheTTL = 0
+	price, _, err = c.DecodeAndFormatUpdPrice(ctx, priceKey, data, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.InDelta(t, 2612535, price, 1e-9)
+	delete(accounts, priceKey)
+	_, _, err = c.DecodeAndFormatUpdPrice(ctx, priceKey, data, rpc.CommitmentProcessed)
+	assert.Error(t, err)
+
+	_, _, err = c.DecodeAndFormatUpdPrice(ctx, priceKey, caseInitMapping, rpc.CommitmentProcessed)
+	assert.EqualError(t, err, "not a price update: init_mapping")
+	_, _, err = c.DecodeAndFormatUpdPrice(ctx, solana.SysVarClockPubkey, data, rpc.CommitmentProcessed)
+	assert.EqualError(t, err, "error getting price account SysvarC1ock11111111111111111111111111111111: not found")
+}
+
+func TestClient_CountPriceAccounts(t *testing.T) {
+	productKey := solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko")
+	firstPriceKey := solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh")
+	secondPriceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+
+	// Link first price account to second price account.
+	firstPrice := append([]byte{}, casePriceAccount...)
+	copy(firstPrice[144:176], secondPriceKey[:])
+
+	server := newAccountsTestServer(t, map[solana.PublicKey][]byte{
+		productKey:     caseProductAccount,
+		firstPriceKey:  firstPrice,
+		secondPriceKey: casePriceAccount,
+	})
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	count, err := c.CountPriceAccounts(context.Background(), productKey, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = c.CountPriceAccounts(context.Background(), firstPriceKey, rpc.CommitmentProcessed)
+	assert.EqualError(t, err, "E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh is not a product account")
+}
@@ -0,0 +1,236 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"go.uber.org/zap"
+)
+
+// KeyedPriceUpdate is an update of a price account subscribed to with Client.SubscribePrices.
+type KeyedPriceUpdate struct {
+	*PriceAccount
+	Key  solana.PublicKey
+	Slot uint64
+}
+
+// MarshalJSON encodes the price account as PriceAccount.MarshalJSON, along with its key and slot.
+func (u KeyedPriceUpdate) MarshalJSON() ([]byte, error) {
+	fields, prices := priceAccountJSON(u.PriceAccount)
+	return json.Marshal(struct {
+		*priceAccountFields
+		Prices *PriceAccountJSON `json:",omitempty"`
+		Key    solana.PublicKey
+		Slot   uint64
+	}{fields, prices, u.Key, u.Slot})
+}
+
+// subscribeRetryInterval is the time to wait before reconnecting a broken subscription.
+var subscribeRetryInterval = 3 * time.Second
+
+// SubscribePrices subscribes to updates of the given price accounts over a single WebSocket connection.
+//
+// Subscriptions are created one account at a time, limited to Client.SubscribeRate requests per second.
+// If the WebSocket connection breaks, it reconnects and resubscribes to all accounts.
+// Updates of accounts that are not price accounts are dropped.
+//
+// An error is returned if the initial subscriptions cannot be created.
+// The returned channel is closed when the context is canceled.
+func (c *Client) SubscribePrices(ctx context.Context, keys []solana.PublicKey) (<-chan KeyedPriceUpdate, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no price accounts to subscribe to")
+	}
+	keys = dedupKeys(keys)
+
+	subs, err := c.subscribeAccounts(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan KeyedPriceUpdate)
+	go func() {
+		defer close(updates)
+		_ = backoff.Retry(func() error {
+			if subs == nil {
+				var err error
+				if subs, err = c.subscribeAccounts(ctx, keys); err != nil {
+					return c.retrySubscriptions(err)
+				}
+			}
+			err := subs.run(ctx, c, updates)
+			subs.close()
+			subs = nil
+			return c.retrySubscriptions(err)
+		}, backoff.WithContext(backoff.NewConstantBackOff(subscribeRetryInterval), ctx))
+	}()
+	return updates, nil
+}
+
+// retrySubscriptions decides whether to retry after subscriptions failed with the given error.
+func (c *Client) retrySubscriptions(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return backoff.Permanent(err)
+	}
+	c.Log.Error("Price subscriptions failed, restarting", zap.Error(err))
+	return err
+}
+
+// accountSubscriptions is a set of account subscriptions sharing a WebSocket connection.
+type accountSubscriptions struct {
+	client *ws.Client
+	keys   []solana.PublicKey
+	subs   []*ws.AccountSubscription
+}
+
+// subscribeAccounts connects to the WebSocket API and subscribes to each of the given accounts.
+func (c *Client) subscribeAccounts(ctx context.Context, keys []solana.PublicKey) (*accountSubscriptions, error) {
+	client, err := ws.Connect(ctx, c.WebSocketURL)
+	if err != nil {
+		return nil, err
+	}
+	metricsWsActiveConns.Inc()
+	s := &accountSubscriptions{
+		client: client,
+		keys:   keys,
+		subs:   make([]*ws.AccountSubscription, 0, len(keys)),
+	}
+
+	rate := c.SubscribeRate
+	if rate <= 0 {
+		rate = 1
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	for i, key := range keys {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				s.close()
+				return nil, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		sub, err := client.AccountSubscribeWithOpts(key, rpc.CommitmentProcessed, solana.EncodingBase64Zstd)
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		s.subs = append(s.subs, sub)
+	}
+	return s, nil
+}
+
+// close terminates the WebSocket connection.
+func (s *accountSubscriptions) close() {
+	metricsWsActiveConns.Dec()
+	s.client.Close()
+}
+
+type accountUpdate struct {
+	key    solana.PublicKey
+	result *ws.AccountResult
+}
+
+// run forwards price account updates of all subscriptions until the first subscription fails.
+func (s *accountSubscriptions) run(ctx context.Context, c *Client, updates chan<- KeyedPriceUpdate) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan accountUpdate)
+	errs := make(chan error, len(s.subs))
+	for i := range s.subs {
+		go func(key solana.PublicKey, sub *ws.AccountSubscription) {
+			for {
+				res, err := sub.Recv()
+				if err == nil && res == nil {
+					err = net.ErrClosed
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case results <- accountUpdate{key: key, result: res}:
+				}
+			}
+		}(s.keys[i], s.subs[i])
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case update := <-results:
+			metricsWsEventsTotal.Inc()
+			msg, ok := c.decodeKeyedPriceUpdate(update)
+			if !ok {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case updates <- msg:
+			}
+		}
+	}
+}
+
+// decodeKeyedPriceUpdate decodes an account notification into a price update.
+func (c *Client) decodeKeyedPriceUpdate(update accountUpdate) (KeyedPriceUpdate, bool) {
+	if update.result.Value.Owner != c.Env.Program {
+		return KeyedPriceUpdate{}, false
+	}
+	accountData := update.result.Value.Data.GetBinary()
+	if PeekAccount(accountData) != AccountTypePrice {
+		return KeyedPriceUpdate{}, false
+	}
+	priceAcc := new(PriceAccount)
+	if err := priceAcc.UnmarshalBinary(accountData); err != nil {
+		c.Log.Warn("Failed to unmarshal price account", zap.Error(err))
+		return KeyedPriceUpdate{}, false
+	}
+	return KeyedPriceUpdate{
+		PriceAccount: priceAcc,
+		Key:          update.key,
+		Slot:         update.result.Context.Slot,
+	}, true
+}
+
+// dedupKeys returns the given keys with duplicates removed, preserving order.
+func dedupKeys(keys []solana.PublicKey) []solana.PublicKey {
+	seen := make(map[solana.PublicKey]struct{}, len(keys))
+	out := make([]solana.PublicKey, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, key)
+	}
+	return out
+}
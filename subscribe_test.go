@@ -0,0 +1,142 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSubscribeTestServer serves accountSubscribe requests over WebSocket.
+//
+// After all keys are subscribed, it sends one notification per key with the connection number as slot.
+// The first connection is closed once dropFirst is closed to force a reconnect.
+func newSubscribeTestServer(t *testing.T, numKeys int, data []byte, dropFirst <-chan struct{}) *httptest.Server {
+	var upgrader websocket.Upgrader
+	var numConns uint64
+	return httptest.NewServer(http.HandlerFunc(func(wr http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(wr, req, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		numConns++
+		slot := numConns
+
+		var subs []string
+		for len(subs) < numKeys {
+			var call struct {
+				ID     uint64        `json:"id"`
+				Method string        `json:"method"`
+				Params []interface{} `json:"params"`
+			}
+			if err := conn.ReadJSON(&call); err != nil {
+				return
+			}
+			require.Equal(t, "accountSubscribe", call.Method)
+			subs = append(subs, call.Params[0].(string))
+			require.NoError(t, conn.WriteJSON(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      call.ID,
+				"result":  len(subs),
+			}))
+		}
+		for i := range subs {
+			require.NoError(t, conn.WriteJSON(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"method":  "accountNotification",
+				"params": map[string]interface{}{
+					"subscription": i + 1,
+					"result": map[string]interface{}{
+						"context": map[string]interface{}{"slot": slot},
+						"value": map[string]interface{}{
+							"data":       []string{base64.StdEncoding.EncodeToString(data), "base64"},
+							"executable": false,
+							"lamports":   23942400,
+							"owner":      Devnet.Program.String(),
+							"rentEpoch":  274,
+						},
+					},
+				},
+			}))
+		}
+		if slot == 1 {
+			<-dropFirst
+			return
+		}
+		// Keep connection open until client disconnects.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestClient_SubscribePrices(t *testing.T) {
+	defer func(interval time.Duration) { subscribeRetryInterval = interval }(subscribeRetryInterval)
+	subscribeRetryInterval = 10 * time.Millisecond
+
+	keys := []solana.PublicKey{
+		solana.MustPublicKeyFromBase58("E36MyBbavhYKHVLWR79GiReNNnBDiHj6nWA7htbkNZbh"),
+		solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw"),
+	}
+	dropFirst := make(chan struct{})
+	server := newSubscribeTestServer(t, len(keys), casePriceAccount, dropFirst)
+	defer server.Close()
+
+	client := NewClient(Devnet, server.URL, "ws"+strings.TrimPrefix(server.URL, "http"))
+	client.SubscribeRate = 1000
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Duplicate keys are only subscribed once.
+	updates, err := client.SubscribePrices(ctx, append(keys, keys[0]))
+	require.NoError(t, err)
+
+	// Expect updates from both the first and the reconnected second connection.
+	for slot := uint64(1); slot <= 2; slot++ {
+		var received []solana.PublicKey
+		for range keys {
+			update, ok := <-updates
+			require.True(t, ok)
+			assert.Equal(t, slot, update.Slot)
+			assert.Equal(t, AccountTypePrice, update.AccountType)
+			received = append(received, update.Key)
+		}
+		assert.ElementsMatch(t, keys, received)
+		if slot == 1 {
+			close(dropFirst)
+		}
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestClient_SubscribePrices_NoKeys(t *testing.T) {
+	client := NewClient(Devnet, testRPC, testWS)
+	_, err := client.SubscribePrices(context.Background(), nil)
+	assert.EqualError(t, err, "no price accounts to subscribe to")
+}
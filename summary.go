@@ -0,0 +1,87 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+// InstructionSummary is a flat representation of an instruction suitable for display.
+type InstructionSummary struct {
+	Command  string                 `json:"command" yaml:"command"`   // human-readable instruction name
+	Program  string                 `json:"program" yaml:"program"`   // program ID
+	Accounts []AccountSummary       `json:"accounts" yaml:"accounts"` // accounts in instruction order
+	Fields   map[string]interface{} `json:"fields" yaml:"fields"`     // payload fields
+}
+
+// AccountSummary describes an account referenced by an instruction.
+type AccountSummary struct {
+	Label    string `json:"label,omitempty" yaml:"label,omitempty"` // role of the account, see AccountLabels
+	Pubkey   string `json:"pubkey" yaml:"pubkey"`
+	Signer   bool   `json:"signer" yaml:"signer"`
+	Writable bool   `json:"writable" yaml:"writable"`
+}
+
+// Summary returns a flat representation of the instruction.
+//
+// The payload is flattened into the Fields map using snake_case keys.
+// Price statuses are represented by their names.
+// Accounts are labeled by their role as returned by AccountLabels.
+func (inst *Instruction) Summary() InstructionSummary {
+	labeled := inst.LabeledAccounts()
+	accounts := make([]AccountSummary, len(labeled))
+	for i, acc := range labeled {
+		accounts[i] = AccountSummary{
+			Label:    acc.Role,
+			Pubkey:   acc.Meta.PublicKey.String(),
+			Signer:   acc.Meta.IsSigner,
+			Writable: acc.Meta.IsWritable,
+		}
+	}
+	return InstructionSummary{
+		Command:  InstructionIDToName(inst.Header.Cmd),
+		Program:  inst.programKey.String(),
+		Accounts: accounts,
+		Fields:   payloadFields(inst.Payload),
+	}
+}
+
+// payloadFields flattens an instruction payload into a map.
+func payloadFields(payload interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	switch p := payload.(type) {
+	case *CommandUpdProduct:
+		fields["attrs"] = p.KVs()
+	case *CommandAddPrice:
+		fields["exponent"] = p.Exponent
+		fields["price_type"] = p.PriceType
+	case *CommandInitPrice:
+		fields["exponent"] = p.Exponent
+		fields["price_type"] = p.PriceType
+	case *CommandSetMinPub:
+		fields["min_pub"] = p.MinPub
+	case *CommandAddPublisher:
+		fields["publisher"] = p.Publisher.String()
+	case *CommandDelPublisher:
+		fields["publisher"] = p.Publisher.String()
+	case *CommandUpdPrice:
+		fields["status"] = p.Status.String()
+		fields["price"] = p.Price
+		fields["conf"] = p.Conf
+		fields["pub_slot"] = p.PubSlot
+	case *CommandUpdTest:
+		fields["exponent"] = p.Exponent
+		fields["slot_diff"] = p.SlotDiff
+		fields["price"] = p.Price
+		fields["conf"] = p.Conf
+	}
+	return fields
+}
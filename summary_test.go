@@ -0,0 +1,65 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstruction_Summary(t *testing.T) {
+	var env = Devnet
+	var accs = []*solana.AccountMeta{
+		solana.Meta(solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")).SIGNER().WRITE(),
+		solana.Meta(solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}
+
+	ins, err := DecodeInstruction(env.Program, accs, caseUpdPrice)
+	require.NoError(t, err)
+
+	jsonData, err := json.Marshal(ins.Summary())
+	require.NoError(t, err)
+
+	//language=JSON
+	expected := `{
+		"command": "upd_price",
+		"program": "gSbePebfvPy7tRqimPoVecS2UsBvYv46ynrzWocc92s",
+		"accounts": [
+			{"label": "funding", "pubkey": "5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7", "signer": true, "writable": true},
+			{"label": "price", "pubkey": "EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw", "signer": false, "writable": true},
+			{"label": "clock", "pubkey": "SysvarC1ock11111111111111111111111111111111", "signer": false, "writable": false}
+		],
+		"fields": {
+			"status": "trading",
+			"price": 261253500000,
+			"conf": 120500000,
+			"pub_slot": 118774432
+		}
+	}`
+	assert.JSONEq(t, expected, string(jsonData))
+}
+
+func TestInstruction_Summary_NoPayload(t *testing.T) {
+	key := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	summary := NewInstructionBuilder(Devnet.Program).AggPrice(key, key).Summary()
+	assert.Equal(t, "agg_price", summary.Command)
+	assert.Len(t, summary.Accounts, 3)
+	assert.Empty(t, summary.Fields)
+}
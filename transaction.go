@@ -0,0 +1,412 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// AddressLookupTableResolver returns the addresses stored in an address lookup table account.
+type AddressLookupTableResolver func(table solana.PublicKey) ([]solana.PublicKey, error)
+
+// WithLookupTables resolves accounts loaded from address lookup tables when decoding versioned (v0) transactions.
+//
+// Only used by DecodeInnerInstructions and DecodeTransactionInstructions,
+// since rpc.GetTransactionResult does not carry the loaded addresses.
+func WithLookupTables(resolve AddressLookupTableResolver) DecodeOption {
+	return func(o *decodeOptions) {
+		o.lookupTables = resolve
+	}
+}
+
+// DecodedInstruction is a Pyth instruction along with its position in the original transaction.
+type DecodedInstruction struct {
+	// Index is the index of the top-level instruction among all instructions of the transaction.
+	// For inner instructions, this is the index of the top-level instruction that invoked them.
+	Index int
+	// InnerIndex is the index among the inner instructions of Index, or -1 for top-level instructions.
+	InnerIndex int
+	Inst       *Instruction
+}
+
+// DecodeInnerInstructions decodes all Pyth instructions of a transaction returned by getTransaction.
+//
+// Walks top-level instructions and inner instructions invoked via CPI in execution order.
+// Instructions of programs other than the known Pyth deployments are skipped.
+// The transaction must have been requested with a binary encoding (base58 or base64).
+//
+// Both legacy and versioned (v0) transactions are supported.
+// Accounts loaded from address lookup tables are resolved via WithLookupTables.
+// Options are also passed on to DecodeInstruction.
+func DecodeInnerInstructions(tx *rpc.GetTransactionResult, opts ...DecodeOption) ([]*Instruction, error) {
+	decoded, err := DecodeTransactionInstructions(tx, opts...)
+	return decodedInstructions(decoded), err
+}
+
+// DecodeTransactionInstructions is like DecodeInnerInstructions,
+// but also returns the position of each Pyth instruction among all instructions of the transaction.
+func DecodeTransactionInstructions(tx *rpc.GetTransactionResult, opts ...DecodeOption) ([]DecodedInstruction, error) {
+	if tx == nil || tx.Transaction == nil {
+		return nil, errors.New("missing transaction")
+	}
+	txData := tx.Transaction.GetBinary()
+	if len(txData) == 0 {
+		return nil, errors.New("missing binary transaction data")
+	}
+	var o decodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	msg, err := parseRawTransaction(txData)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := msg.resolveKeys(o.lookupTables)
+	if err != nil {
+		return nil, err
+	}
+	var inner []rpc.InnerInstruction
+	if tx.Meta != nil {
+		inner = tx.Meta.InnerInstructions
+	}
+	return decodeRawMessage(msg, keys, inner, opts...)
+}
+
+// DecodeTransactionInstructionsV0 decodes all top-level Pyth instructions of a serialized transaction.
+//
+// Both legacy and versioned (v0) transactions are supported.
+// Accounts loaded from address lookup tables are resolved using resolve,
+// which may be nil if the transaction does not use lookup tables.
+func DecodeTransactionInstructionsV0(txData []byte, resolve AddressLookupTableResolver) ([]*Instruction, error) {
+	msg, err := parseRawTransaction(txData)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := msg.resolveKeys(resolve)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeRawMessage(msg, keys, nil)
+	return decodedInstructions(decoded), err
+}
+
+// GetInstructions fetches a transaction by signature and decodes all of its Pyth instructions.
+//
+// Walks top-level instructions and inner instructions invoked via CPI in execution order.
+// Versioned (v0) transactions are supported,
+// using the addresses loaded from lookup tables as reported by the RPC node.
+// RPC nodes only serve transactions at the confirmed or finalized commitment level.
+func (c *Client) GetInstructions(ctx context.Context, sig solana.Signature, commitment rpc.CommitmentType) ([]*Instruction, error) {
+	var res *struct {
+		Transaction *rpc.TransactionResultEnvelope `json:"transaction"`
+		Meta        *struct {
+			InnerInstructions []rpc.InnerInstruction `json:"innerInstructions"`
+			LoadedAddresses   struct {
+				Writable []solana.PublicKey `json:"writable"`
+				Readonly []solana.PublicKey `json:"readonly"`
+			} `json:"loadedAddresses"`
+		} `json:"meta"`
+	}
+	err := c.RPC.RPCCallForInto(ctx, &res, "getTransaction", []interface{}{
+		sig.String(),
+		map[string]interface{}{
+			"encoding":                       solana.EncodingBase64,
+			"commitment":                     commitment,
+			"maxSupportedTransactionVersion": 0,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || res.Transaction == nil {
+		return nil, fmt.Errorf("transaction %s not found", sig)
+	}
+
+	msg, err := parseRawTransaction(res.Transaction.GetBinary())
+	if err != nil {
+		return nil, err
+	}
+	keys := msg.staticKeys
+	var inner []rpc.InnerInstruction
+	if res.Meta != nil {
+		// The RPC node already resolved lookup tables, in the order expected by accountMetas.
+		keys = append(append(append([]solana.PublicKey{}, keys...),
+			res.Meta.LoadedAddresses.Writable...), res.Meta.LoadedAddresses.Readonly...)
+		inner = res.Meta.InnerInstructions
+	}
+	decoded, err := decodeRawMessage(msg, keys, inner)
+	return decodedInstructions(decoded), err
+}
+
+// parseRawTransaction decodes the message of a serialized legacy or v0 transaction.
+func parseRawTransaction(txData []byte) (*rawMessage, error) {
+	msgData, err := transactionMessage(txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	msg, err := parseRawMessage(msgData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	return msg, nil
+}
+
+// decodeRawMessage decodes the top-level and inner Pyth instructions of a message in execution order.
+//
+// Keys must contain the static keys of the message followed by all loaded addresses, see rawMessage.accountMetas.
+func decodeRawMessage(
+	msg *rawMessage,
+	keys []solana.PublicKey,
+	innerLists []rpc.InnerInstruction,
+	opts ...DecodeOption,
+) ([]DecodedInstruction, error) {
+	metas, err := msg.accountMetas(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	inner := make(map[uint16][]solana.CompiledInstruction)
+	for _, list := range innerLists {
+		inner[list.Index] = append(inner[list.Index], list.Instructions...)
+	}
+
+	var decoded []DecodedInstruction
+	for i := range msg.instructions {
+		inst, err := decodeRawInstruction(metas, &msg.instructions[i], opts...)
+		if err != nil {
+			return decoded, fmt.Errorf("failed to decode instruction #%d: %w", i, err)
+		}
+		if inst != nil {
+			decoded = append(decoded, DecodedInstruction{Index: i, InnerIndex: -1, Inst: inst})
+		}
+		for j := range inner[uint16(i)] {
+			raw, err := toRawInstruction(&inner[uint16(i)][j])
+			if err == nil {
+				inst, err = decodeRawInstruction(metas, raw, opts...)
+			}
+			if err != nil {
+				return decoded, fmt.Errorf("failed to decode inner instruction #%d.%d: %w", i, j, err)
+			}
+			if inst != nil {
+				decoded = append(decoded, DecodedInstruction{Index: i, InnerIndex: j, Inst: inst})
+			}
+		}
+	}
+	return decoded, nil
+}
+
+// decodedInstructions strips the positions of decoded instructions.
+func decodedInstructions(decoded []DecodedInstruction) []*Instruction {
+	if decoded == nil {
+		return nil
+	}
+	insts := make([]*Instruction, len(decoded))
+	for i := range decoded {
+		insts[i] = decoded[i].Inst
+	}
+	return insts
+}
+
+// transactionMessage returns the serialized message of a serialized transaction, skipping its signatures.
+func transactionMessage(txData []byte) ([]byte, error) {
+	dec := bin.NewBinDecoder(txData)
+	numSigs, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.SkipBytes(uint(numSigs * solana.SignatureLength)); err != nil {
+		return nil, err
+	}
+	return txData[dec.Position():], nil
+}
+
+// toRawInstruction converts an inner instruction as returned by the RPC node to a rawInstruction.
+func toRawInstruction(compiled *solana.CompiledInstruction) (*rawInstruction, error) {
+	if compiled.ProgramIDIndex > math.MaxUint8 {
+		return nil, fmt.Errorf("program index %d out of range", compiled.ProgramIDIndex)
+	}
+	raw := &rawInstruction{
+		programIndex: uint8(compiled.ProgramIDIndex),
+		accounts:     make([]uint8, len(compiled.Accounts)),
+		data:         compiled.Data,
+	}
+	for i, index := range compiled.Accounts {
+		if index > math.MaxUint8 {
+			return nil, fmt.Errorf("account index %d out of range", index)
+		}
+		raw.accounts[i] = uint8(index)
+	}
+	return raw, nil
+}
+
+// isKnownProgram returns whether the given program ID belongs to a known Pyth deployment.
+//
+// See EnvByProgram.
+func isKnownProgram(programKey solana.PublicKey) bool {
+	_, ok := EnvByProgram(programKey)
+	return ok
+}
+
+// MaxTransactionSize is the maximum size in bytes of a serialized Solana transaction.
+const MaxTransactionSize = 1232
+
+// TotalDataLen returns the sum of the instruction data lengths of the given instructions.
+func TotalDataLen(insts []*Instruction) (int, error) {
+	var total int
+	for _, inst := range insts {
+		data, err := inst.Data()
+		if err != nil {
+			return 0, err
+		}
+		total += len(data)
+	}
+	return total, nil
+}
+
+// RequiredSigners returns the keys of all accounts that must sign any of the given instructions,
+// without duplicates and in order of first appearance.
+func RequiredSigners(insts []*Instruction) []solana.PublicKey {
+	var signers []solana.PublicKey
+	for _, inst := range insts {
+		for _, acc := range inst.Accounts() {
+			if acc.IsSigner && !containsKey(signers, acc.PublicKey) {
+				signers = append(signers, acc.PublicKey)
+			}
+		}
+	}
+	return signers
+}
+
+// PackInstructions greedily groups instructions into batches that each fit into a transaction of maxBytes.
+//
+// The order of instructions is preserved.
+// The size of each batch is estimated as a legacy transaction with one signature per signer account.
+// Accounts and programs referenced by multiple instructions of a batch are counted once.
+// Returns an error if a single instruction does not fit into maxBytes.
+func PackInstructions(insts []*Instruction, maxBytes int) ([][]*Instruction, error) {
+	var batches [][]*Instruction
+	var batch []*Instruction
+	est := newTxSizeEstimate()
+	for i, inst := range insts {
+		data, err := inst.Data()
+		if err != nil {
+			return nil, fmt.Errorf("instruction #%d: %w", i, err)
+		}
+		if len(batch) > 0 && est.sizeWith(inst, len(data)) > maxBytes {
+			batches = append(batches, batch)
+			batch = nil
+			est = newTxSizeEstimate()
+		}
+		if est.sizeWith(inst, len(data)) > maxBytes {
+			return nil, fmt.Errorf("instruction #%d (%s) does not fit into %d bytes",
+				i, InstructionIDToName(inst.Header.Cmd), maxBytes)
+		}
+		est.add(inst, len(data))
+		batch = append(batch, inst)
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// txSizeEstimate tracks the serialized size of a legacy transaction while instructions are added.
+type txSizeEstimate struct {
+	keys      map[solana.PublicKey]bool // value indicates whether the key signs
+	numSigs   int
+	numInsts  int
+	instsSize int // size of compiled instructions
+}
+
+func newTxSizeEstimate() *txSizeEstimate {
+	return &txSizeEstimate{keys: make(map[solana.PublicKey]bool)}
+}
+
+// add adds an instruction with the given data length to the transaction.
+func (e *txSizeEstimate) add(inst *Instruction, dataLen int) {
+	e.addKey(inst.programKey, false)
+	for _, acc := range inst.accounts {
+		e.addKey(acc.PublicKey, acc.IsSigner)
+	}
+	e.numInsts++
+	e.instsSize += compiledInstructionLen(len(inst.accounts), dataLen)
+}
+
+func (e *txSizeEstimate) addKey(key solana.PublicKey, signer bool) {
+	isSigner, ok := e.keys[key]
+	if signer && !isSigner {
+		e.numSigs++
+	}
+	if !ok || signer {
+		e.keys[key] = signer || isSigner
+	}
+}
+
+// sizeWith returns the estimated size of the serialized transaction if the given instruction was added.
+func (e *txSizeEstimate) sizeWith(inst *Instruction, dataLen int) int {
+	numKeys, numSigs := len(e.keys), e.numSigs
+	seen := make(map[solana.PublicKey]bool)
+	countKey := func(key solana.PublicKey, signer bool) {
+		isSigner, ok := seen[key]
+		if !ok {
+			isSigner, ok = e.keys[key]
+		}
+		if !ok {
+			numKeys++
+		}
+		if signer && !isSigner {
+			numSigs++
+		}
+		seen[key] = signer || isSigner
+	}
+	countKey(inst.programKey, false)
+	for _, acc := range inst.accounts {
+		countKey(acc.PublicKey, acc.IsSigner)
+	}
+	if numSigs == 0 {
+		numSigs = 1 // fee payer
+	}
+	return compactU16Len(numSigs) + numSigs*solana.SignatureLength +
+		3 + // message header
+		compactU16Len(numKeys) + numKeys*solana.PublicKeyLength +
+		32 + // recent blockhash
+		compactU16Len(e.numInsts+1) + e.instsSize + compiledInstructionLen(len(inst.accounts), dataLen)
+}
+
+// compiledInstructionLen returns the serialized size of a compiled instruction.
+func compiledInstructionLen(numAccounts int, dataLen int) int {
+	return 1 + // program index
+		compactU16Len(numAccounts) + numAccounts +
+		compactU16Len(dataLen) + dataLen
+}
+
+// compactU16Len returns the size of n in the compact-u16 encoding.
+func compactU16Len(n int) int {
+	switch {
+	case n < 0x80:
+		return 1
+	case n < 0x4000:
+		return 2
+	default:
+		return 3
+	}
+}
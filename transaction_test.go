@@ -0,0 +1,366 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTransactionResult wraps a transaction into a getTransaction result with the given inner instructions.
+func newTestTransactionResult(t *testing.T, tx *solana.Transaction, inner []rpc.InnerInstruction) *rpc.GetTransactionResult {
+	txData, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	innerJSON, err := json.Marshal(inner)
+	require.NoError(t, err)
+
+	var res rpc.GetTransactionResult
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"slot": 118774432,
+		"transaction": ["`+base64.StdEncoding.EncodeToString(txData)+`", "base64"],
+		"meta": {
+			"err": null,
+			"innerInstructions": `+string(innerJSON)+`
+		}
+	}`), &res))
+	return &res
+}
+
+func TestDecodeInnerInstructions(t *testing.T) {
+	signer := solana.NewWallet().PrivateKey
+	publisher := signer.PublicKey()
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	aggregator := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	builder := NewInstructionBuilder(Devnet.Program)
+	updPrice := builder.UpdPrice(publisher, priceKey, CommandUpdPrice{
+		Status:  PriceStatusTrading,
+		Price:   261253500000,
+		Conf:    120500000,
+		PubSlot: 118774432,
+	})
+	// Some other program invoking Pyth via CPI.
+	other := solana.NewInstruction(aggregator, solana.AccountMetaSlice{
+		solana.Meta(priceKey).WRITE(),
+		solana.Meta(Devnet.Program),
+	}, []byte{0x01})
+
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{other, updPrice},
+		solana.Hash{},
+		solana.TransactionPayer(publisher),
+	)
+	require.NoError(t, err)
+	_, err = tx.Sign(func(solana.PublicKey) *solana.PrivateKey {
+		return &signer
+	})
+	require.NoError(t, err)
+
+	// Re-use the compiled upd_price as the inner instruction of the aggregator.
+	innerUpdPrice := tx.Message.Instructions[1]
+	res := newTestTransactionResult(t, tx, []rpc.InnerInstruction{
+		{
+			Index:        0,
+			Instructions: []solana.CompiledInstruction{innerUpdPrice},
+		},
+	})
+
+	insts, err := DecodeInnerInstructions(res)
+	require.NoError(t, err)
+	require.Len(t, insts, 2)
+	for _, inst := range insts {
+		assert.Equal(t, Devnet.Program, inst.ProgramID())
+		assert.Equal(t, Instruction_UpdPrice, inst.Header.Cmd)
+		assert.Equal(t, updPrice.Payload, inst.Payload)
+		require.Len(t, inst.Accounts(), 3)
+		assert.Equal(t, publisher, inst.Accounts()[0].PublicKey)
+		assert.Equal(t, priceKey, inst.Accounts()[1].PublicKey)
+	}
+
+	t.Run("Indices", func(t *testing.T) {
+		decoded, err := DecodeTransactionInstructions(res)
+		require.NoError(t, err)
+		require.Len(t, decoded, 2)
+		assert.Equal(t, 0, decoded[0].Index)
+		assert.Equal(t, 0, decoded[0].InnerIndex)
+		assert.Equal(t, 1, decoded[1].Index)
+		assert.Equal(t, -1, decoded[1].InnerIndex)
+		assert.Equal(t, insts[0], decoded[0].Inst)
+		assert.Equal(t, insts[1], decoded[1].Inst)
+	})
+
+	t.Run("Versioned", func(t *testing.T) {
+		txData, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		// Turn legacy message into a v0 message by inserting the version prefix
+		// and appending an empty list of lookup tables.
+		msgOffset := 1 + len(tx.Signatures)*solana.SignatureLength
+		versioned := append(append(append([]byte{}, txData[:msgOffset]...), 0x80), txData[msgOffset:]...)
+		versioned = append(versioned, 0)
+
+		var res rpc.GetTransactionResult
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"slot": 118774432,
+			"transaction": ["`+base64.StdEncoding.EncodeToString(versioned)+`", "base64"]
+		}`), &res))
+		versionedInsts, err := DecodeInnerInstructions(&res)
+		require.NoError(t, err)
+		assert.Equal(t, insts[1:], versionedInsts)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := DecodeInnerInstructions(&rpc.GetTransactionResult{})
+		assert.EqualError(t, err, "missing transaction")
+	})
+}
+
+func TestDecodeTransactionInstructionsV0(t *testing.T) {
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	table := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	// Static keys: publisher, program. Loaded: price (writable), clock (read-only).
+	msg := []byte{0x80, 1, 0, 1, 2}
+	msg = append(msg, publisher[:]...)
+	msg = append(msg, Devnet.Program[:]...)
+	msg = append(msg, make([]byte, 32)...) // blockhash
+	msg = append(msg, 1)                   // one instruction
+	msg = append(msg, 1, 3, 0, 2, 3)       // program index, account indexes
+	msg = append(msg, byte(len(caseUpdPrice)))
+	msg = append(msg, caseUpdPrice...)
+	msg = append(msg, 1) // one lookup table
+	msg = append(msg, table[:]...)
+	msg = append(msg, 1, 2) // writable indexes
+	msg = append(msg, 1, 0) // read-only indexes
+	txData := append(append([]byte{1}, make([]byte, solana.SignatureLength)...), msg...)
+
+	resolve := func(key solana.PublicKey) ([]solana.PublicKey, error) {
+		require.Equal(t, table, key)
+		return []solana.PublicKey{solana.SysVarClockPubkey, solana.SystemProgramID, priceKey}, nil
+	}
+	insts, err := DecodeTransactionInstructionsV0(txData, resolve)
+	require.NoError(t, err)
+	require.Len(t, insts, 1)
+	assert.Equal(t, Instruction_UpdPrice, insts[0].Header.Cmd)
+	assert.Equal(t, []*solana.AccountMeta{
+		solana.Meta(publisher).SIGNER().WRITE(),
+		solana.Meta(priceKey).WRITE(),
+		solana.Meta(solana.SysVarClockPubkey),
+	}, insts[0].Accounts())
+
+	_, err = DecodeTransactionInstructionsV0(txData, nil)
+	assert.EqualError(t, err, "message loads accounts from 1 lookup tables")
+
+	// Inner instructions resolve accounts the same way as top-level instructions.
+	var res rpc.GetTransactionResult
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"slot": 118774432,
+		"transaction": ["`+base64.StdEncoding.EncodeToString(txData)+`", "base64"],
+		"meta": {
+			"err": null,
+			"innerInstructions": [{
+				"index": 0,
+				"instructions": [{
+					"programIdIndex": 1,
+					"accounts": [0, 2, 3],
+					"data": "`+solana.Base58(caseUpdPrice).String()+`"
+				}]
+			}]
+		}
+	}`), &res))
+	decoded, err := DecodeTransactionInstructions(&res, WithLookupTables(resolve))
+	require.NoError(t, err)
+	require.Len(t, decoded, 2)
+	assert.Equal(t, -1, decoded[0].InnerIndex)
+	assert.Equal(t, 0, decoded[1].InnerIndex)
+	assert.Equal(t, insts[0], decoded[0].Inst)
+	assert.Equal(t, insts[0], decoded[1].Inst)
+	_, err = DecodeInnerInstructions(&res)
+	assert.EqualError(t, err, "message loads accounts from 1 lookup tables")
+
+	_, err = DecodeTransactionInstructionsV0(txData, func(solana.PublicKey) ([]solana.PublicKey, error) {
+		return []solana.PublicKey{solana.SysVarClockPubkey}, nil
+	})
+	assert.EqualError(t, err, "index 2 out of range of lookup table "+table.String())
+
+	_, err = DecodeTransactionInstructionsV0(txData, func(solana.PublicKey) ([]solana.PublicKey, error) {
+		return nil, errors.New("not found")
+	})
+	assert.EqualError(t, err, "failed to resolve lookup table "+table.String()+": not found")
+}
+
+func TestClient_GetInstructions(t *testing.T) {
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+	table := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+
+	// Static keys: publisher, program. Loaded: price (writable), clock (read-only).
+	msg := []byte{0x80, 1, 0, 1, 2}
+	msg = append(msg, publisher[:]...)
+	msg = append(msg, Devnet.Program[:]...)
+	msg = append(msg, make([]byte, 32)...) // blockhash
+	msg = append(msg, 1)                   // one instruction
+	msg = append(msg, 1, 3, 0, 2, 3)       // program index, account indexes
+	msg = append(msg, byte(len(caseUpdPrice)))
+	msg = append(msg, caseUpdPrice...)
+	msg = append(msg, 1) // one lookup table
+	msg = append(msg, table[:]...)
+	msg = append(msg, 1, 7) // writable indexes
+	msg = append(msg, 1, 9) // read-only indexes
+	txData := append(append([]byte{1}, make([]byte, solana.SignatureLength)...), msg...)
+
+	sig := solana.SignatureFromBytes(make([]byte, solana.SignatureLength))
+	server := httptest.NewServer(http.HandlerFunc(func(wr http.ResponseWriter, req *http.Request) {
+		buf, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"jsonrpc": "2.0",
+			"id": 0,
+			"method": "getTransaction",
+			"params": [
+				"`+sig.String()+`",
+				{
+					"commitment": "finalized",
+					"encoding": "base64",
+					"maxSupportedTransactionVersion": 0
+				}
+			]
+		}`, string(buf))
+
+		_, err = wr.Write([]byte(`{
+			"jsonrpc": "2.0",
+			"id": 0,
+			"result": {
+				"slot": 118774432,
+				"transaction": ["` + base64.StdEncoding.EncodeToString(txData) + `", "base64"],
+				"meta": {
+					"err": null,
+					"innerInstructions": [{
+						"index": 0,
+						"instructions": [{
+							"programIdIndex": 1,
+							"accounts": [0, 2, 3],
+							"data": "` + solana.Base58(caseUpdPrice).String() + `"
+						}]
+					}],
+					"loadedAddresses": {
+						"writable": ["` + priceKey.String() + `"],
+						"readonly": ["` + solana.SysVarClockPubkey.String() + `"]
+					}
+				},
+				"version": 0
+			}
+		}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	insts, err := c.GetInstructions(context.Background(), sig, rpc.CommitmentFinalized)
+	require.NoError(t, err)
+	require.Len(t, insts, 2)
+	for _, inst := range insts {
+		assert.Equal(t, Instruction_UpdPrice, inst.Header.Cmd)
+		assert.Equal(t, []*solana.AccountMeta{
+			solana.Meta(publisher).SIGNER().WRITE(),
+			solana.Meta(priceKey).WRITE(),
+			solana.Meta(solana.SysVarClockPubkey),
+		}, inst.Accounts())
+	}
+}
+
+func TestClient_GetInstructions_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(wr http.ResponseWriter, req *http.Request) {
+		_, err := wr.Write([]byte(`{"jsonrpc": "2.0", "id": 0, "result": null}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	c := NewClient(Devnet, server.URL, server.URL)
+	sig := solana.SignatureFromBytes(make([]byte, solana.SignatureLength))
+	_, err := c.GetInstructions(context.Background(), sig, rpc.CommitmentConfirmed)
+	assert.EqualError(t, err, "transaction "+sig.String()+" not found")
+}
+
+func TestRequiredSigners(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	funding := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	publisher := solana.MustPublicKeyFromBase58("5U3bH5b6XtG99aVWLqwVzYPVpQiFHytBD68Rz2eFPZd7")
+	priceKey := solana.MustPublicKeyFromBase58("EdVCmQ9FSPcVe5YySXDPCRmc8aDQLKJ9xvYBMZPie1Vw")
+
+	assert.Equal(t, []solana.PublicKey{funding, priceKey, publisher}, RequiredSigners([]*Instruction{
+		builder.AddPublisher(funding, priceKey, CommandAddPublisher{Publisher: publisher}),
+		builder.UpdPrice(publisher, priceKey, CommandUpdPrice{}),
+		builder.SetMinPub(funding, priceKey, CommandSetMinPub{MinPub: 3}),
+	}))
+	assert.Empty(t, RequiredSigners(nil))
+}
+
+func TestPackInstructions(t *testing.T) {
+	builder := NewInstructionBuilder(Devnet.Program)
+	publisher := solana.NewWallet().PublicKey()
+	var insts []*Instruction
+	for i := 0; i < 20; i++ {
+		priceKey := solana.NewWallet().PublicKey()
+		insts = append(insts, builder.UpdPrice(publisher, priceKey, CommandUpdPrice{Price: int64(i)}))
+	}
+
+	batches, err := PackInstructions(insts, MaxTransactionSize)
+	require.NoError(t, err)
+	require.Greater(t, len(batches), 1)
+
+	var packed []*Instruction
+	for _, batch := range batches {
+		packed = append(packed, batch...)
+
+		// Compare the estimate against the actual serialized size.
+		solInsts := make([]solana.Instruction, len(batch))
+		for i, inst := range batch {
+			solInsts[i] = inst
+		}
+		tx, err := solana.NewTransaction(solInsts, solana.Hash{}, solana.TransactionPayer(publisher))
+		require.NoError(t, err)
+		tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+		txData, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(txData), MaxTransactionSize)
+	}
+	assert.Equal(t, insts, packed)
+
+	// Shared accounts are only counted once, so the first batch must hold more than one update.
+	assert.Greater(t, len(batches[0]), 1)
+
+	_, err = PackInstructions(insts, 100)
+	assert.EqualError(t, err, "instruction #0 (upd_price) does not fit into 100 bytes")
+
+	batches, err = PackInstructions(nil, MaxTransactionSize)
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+
+	total, err := TotalDataLen(insts)
+	require.NoError(t, err)
+	assert.Equal(t, 20*len(caseUpdPrice), total)
+}
@@ -0,0 +1,179 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gagliardetto/solana-go"
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML returns the YAML representation of the instruction.
+//
+// The representation is the same as the JSON encoding of Summary.
+func (inst *Instruction) MarshalYAML() (interface{}, error) {
+	return inst.Summary(), nil
+}
+
+// instructionYAML is the YAML representation of an instruction, as produced by MarshalYAML.
+type instructionYAML struct {
+	Command  string `yaml:"command"`
+	Program  string `yaml:"program"`
+	Accounts []struct {
+		Pubkey string `yaml:"pubkey"`
+	} `yaml:"accounts"`
+	Fields payloadYAML `yaml:"fields"`
+}
+
+// payloadYAML holds the union of all payload fields, as produced by Summary.
+type payloadYAML struct {
+	Attrs     map[string]string `yaml:"attrs"`
+	Exponent  int32             `yaml:"exponent"`
+	PriceType uint32            `yaml:"price_type"`
+	MinPub    uint8             `yaml:"min_pub"`
+	Publisher string            `yaml:"publisher"`
+	Status    string            `yaml:"status"`
+	Price     int64             `yaml:"price"`
+	Conf      uint64            `yaml:"conf"`
+	PubSlot   uint64            `yaml:"pub_slot"`
+}
+
+// InstructionsFromYAML reads instructions from YAML documents as produced by MarshalYAML.
+//
+// Each document contains either a single instruction or a list of instructions.
+// The signer and writable flags of accounts are derived from the command, see AccountsFor.
+// Product attributes are read in canonical order, see AttrsMap.Sort.
+// The instructions are validated the same way as by DecodeInstruction.
+// Instructions of type Instruction_UpdTest are not supported.
+func InstructionsFromYAML(r io.Reader) ([]*Instruction, error) {
+	dec := yaml.NewDecoder(r)
+	var insts []*Instruction
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		var entries []instructionYAML
+		if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.SequenceNode {
+			if err := doc.Decode(&entries); err != nil {
+				return nil, err
+			}
+		} else {
+			var entry instructionYAML
+			if err := doc.Decode(&entry); err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+		for _, entry := range entries {
+			inst, err := entry.toInstruction()
+			if err != nil {
+				return nil, fmt.Errorf("instruction #%d: %w", len(insts), err)
+			}
+			insts = append(insts, inst)
+		}
+	}
+	return insts, nil
+}
+
+func (y *instructionYAML) toInstruction() (*Instruction, error) {
+	cmd, ok := instructionIDFromName(y.Command)
+	if !ok {
+		return nil, fmt.Errorf("unsupported command %q", y.Command)
+	}
+	programKey, err := solana.PublicKeyFromBase58(y.Program)
+	if err != nil {
+		return nil, fmt.Errorf("invalid program: %w", err)
+	}
+	keys := make([]solana.PublicKey, len(y.Accounts))
+	for i, acc := range y.Accounts {
+		if keys[i], err = solana.PublicKeyFromBase58(acc.Pubkey); err != nil {
+			return nil, fmt.Errorf("invalid account #%d: %w", i, err)
+		}
+	}
+	accounts, err := AccountsFor(cmd, keys...)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := y.Fields.toPayload(cmd)
+	if err != nil {
+		return nil, err
+	}
+	inst := &Instruction{
+		programKey: programKey,
+		accounts:   accounts,
+		Header:     makeCommandHeader(cmd),
+		Payload:    payload,
+	}
+	data, err := inst.Data()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeInstruction(programKey, accounts, data)
+}
+
+func (p *payloadYAML) toPayload(cmd int32) (interface{}, error) {
+	switch cmd {
+	case Instruction_UpdProduct:
+		attrs, err := NewAttrsMap(p.Attrs)
+		if err != nil {
+			return nil, err
+		}
+		return &CommandUpdProduct{attrs}, nil
+	case Instruction_AddPrice:
+		return &CommandAddPrice{Exponent: p.Exponent, PriceType: p.PriceType}, nil
+	case Instruction_InitPrice:
+		return &CommandInitPrice{Exponent: p.Exponent, PriceType: p.PriceType}, nil
+	case Instruction_SetMinPub:
+		return &CommandSetMinPub{MinPub: p.MinPub}, nil
+	case Instruction_AddPublisher:
+		publisher, err := solana.PublicKeyFromBase58(p.Publisher)
+		if err != nil {
+			return nil, fmt.Errorf("invalid publisher: %w", err)
+		}
+		return &CommandAddPublisher{Publisher: publisher}, nil
+	case Instruction_DelPublisher:
+		publisher, err := solana.PublicKeyFromBase58(p.Publisher)
+		if err != nil {
+			return nil, fmt.Errorf("invalid publisher: %w", err)
+		}
+		return &CommandDelPublisher{Publisher: publisher}, nil
+	case Instruction_UpdPrice, Instruction_UpdPriceNoFailOnError:
+		status, ok := priceStatusFromName(p.Status)
+		if !ok {
+			return nil, fmt.Errorf("unsupported price status %q", p.Status)
+		}
+		return &CommandUpdPrice{Status: status, Price: p.Price, Conf: p.Conf, PubSlot: p.PubSlot}, nil
+	case Instruction_UpdTest:
+		return nil, errors.New("upd_test is not supported")
+	default:
+		return nil, nil
+	}
+}
+
+// priceStatusFromName returns the price status with the given name as returned by PriceStatus.String.
+func priceStatusFromName(name string) (PriceStatus, bool) {
+	for s := PriceStatusUnknown; s <= PriceStatusAuction; s++ {
+		if s.String() == name {
+			return s, true
+		}
+	}
+	return 0, false
+}
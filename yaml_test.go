@@ -0,0 +1,98 @@
+//  Copyright 2022 Blockdaemon Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestInstructionsFromYAML_RoundTrip(t *testing.T) {
+	var insts []*Instruction
+	for _, inst := range canonicalInstructions() {
+		if inst.Header.Cmd != Instruction_UpdTest {
+			insts = append(insts, inst)
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, yaml.NewEncoder(&buf).Encode(insts))
+	actual, err := InstructionsFromYAML(&buf)
+	require.NoError(t, err)
+	require.Len(t, actual, len(insts))
+	for i, inst := range insts {
+		if payload, ok := inst.Payload.(*CommandUpdProduct); ok {
+			// Attributes are read back in canonical order.
+			attrs := AttrsMap{Pairs: append([][2]string(nil), payload.Pairs...)}
+			attrs.Sort()
+			assert.Equal(t, &CommandUpdProduct{attrs}, actual[i].Payload)
+			continue
+		}
+		assert.Equal(t, inst, actual[i])
+	}
+}
+
+func TestInstructionsFromYAML(t *testing.T) {
+	const doc = `
+command: add_product
+program: gSbePebfvPy7tRqimPoVecS2UsBvYv46ynrzWocc92s
+accounts:
+  - pubkey: 7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy
+  - pubkey: BmA9Z6FjioHJPpjT39QazZyhDRUdZy2ezwx4GiDdE2u2
+  - pubkey: EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko
+---
+- command: upd_product
+  program: gSbePebfvPy7tRqimPoVecS2UsBvYv46ynrzWocc92s
+  accounts:
+    - pubkey: 7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy
+    - pubkey: EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko
+  fields:
+    attrs:
+      symbol: FX.EUR/USD
+      asset_type: FX
+`
+	insts, err := InstructionsFromYAML(strings.NewReader(doc))
+	require.NoError(t, err)
+
+	funding := solana.MustPublicKeyFromBase58("7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy")
+	product := solana.MustPublicKeyFromBase58("EWxGfxoPQSNA2744AYdAKmsQZ8F9o9M7oKkvL3VM1dko")
+	builder := NewInstructionBuilder(Devnet.Program)
+	assert.Equal(t, []*Instruction{
+		builder.AddProduct(funding, Devnet.Mapping, product),
+		builder.UpdProduct(funding, product, CommandUpdProduct{AttrsMap{Pairs: [][2]string{
+			{"asset_type", "FX"},
+			{"symbol", "FX.EUR/USD"},
+		}}}),
+	}, insts)
+}
+
+func TestInstructionsFromYAML_Invalid(t *testing.T) {
+	_, err := InstructionsFromYAML(strings.NewReader(`command: foo`))
+	assert.EqualError(t, err, `instruction #0: unsupported command "foo"`)
+
+	_, err = InstructionsFromYAML(strings.NewReader(`
+command: upd_product
+program: gSbePebfvPy7tRqimPoVecS2UsBvYv46ynrzWocc92s
+accounts:
+  - pubkey: 7cVfgArCheMR6Cs4t6vz5rfnqd56vZq4ndaBrY5xkxXy
+`))
+	assert.EqualError(t, err, "instruction #0: expected 2 accounts for upd_product but got 1")
+}